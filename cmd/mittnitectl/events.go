@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mittwald/mittnite/pkg/cli"
+	"github.com/mittwald/mittnite/pkg/proc"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	eventsCommand.Flags().BoolP("follow", "f", false, "keep streaming new events")
+	eventsCommand.Flags().Int("tail", -1, "output last n events")
+	eventsCommand.Flags().BoolP("json", "j", false, "Print events as JSON")
+
+	ctlCommand.AddCommand(eventsCommand)
+}
+
+var eventsCommand = &cobra.Command{
+	Use:   "events",
+	Args:  cobra.NoArgs,
+	Short: "Show job and probe events",
+	Long:  "This command can be used to show state changes of jobs and probes, like phase changes, probe results, file watch triggers and lazy job spin-ups and cool-downs.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
+
+		follow, _ := cmd.Flags().GetBool("follow")
+		tail, _ := cmd.Flags().GetInt("tail")
+		if tail < -1 {
+			tail = -1
+		}
+
+		format := eventLine
+		if printJson, _ := cmd.Flags().GetBool("json"); printJson {
+			format = nil
+		}
+
+		resp := apiClient.Events(follow, tail, format)
+		if err := resp.Print(); err != nil {
+			return fmt.Errorf("failed to print output: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func eventLine(event proc.Event) string {
+	ts := styleNotSet.Render(event.Time.Format(time.RFC3339))
+
+	switch event.Type {
+	case proc.EventTypeJobPhase:
+		previous := ""
+		if event.PreviousPhase != "" {
+			previous = string(event.PreviousPhase) + " → "
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Left,
+			ts, " job ",
+			styleHighlight.Render(event.Job), ": ",
+			previous,
+			styleHighlight.Render(string(event.Phase)),
+		)
+	case proc.EventTypeProbe:
+		state := styleFailed.Render("failing")
+		if event.Healthy != nil && *event.Healthy {
+			state = styleRunning.Render("healthy")
+		}
+		line := lipgloss.JoinHorizontal(lipgloss.Left,
+			ts, " ", event.ProbeKind, " probe ",
+			styleHighlight.Render(event.Probe), ": ",
+			state,
+		)
+		if event.Message != "" {
+			line += " (" + event.Message + ")"
+		}
+		return line
+	case proc.EventTypeFileWatch:
+		return lipgloss.JoinHorizontal(lipgloss.Left,
+			ts, " job ",
+			styleHighlight.Render(event.Job), ": ",
+			event.Message, " ",
+			styleHighlight.Render(event.File),
+		)
+	case proc.EventTypeLazySpinUp:
+		return lipgloss.JoinHorizontal(lipgloss.Left,
+			ts, " job ",
+			styleHighlight.Render(event.Job), ": ",
+			styleRunning.Render("spun up"), " on incoming connection",
+		)
+	case proc.EventTypeLazyCoolDown:
+		return lipgloss.JoinHorizontal(lipgloss.Left,
+			ts, " job ",
+			styleHighlight.Render(event.Job), ": ",
+			styleStopped.Render("cooled down"), " after inactivity",
+		)
+	default:
+		return lipgloss.JoinHorizontal(lipgloss.Left, ts, " ", string(event.Type), " ", event.Job)
+	}
+}
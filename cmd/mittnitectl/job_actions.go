@@ -94,7 +94,7 @@ func buildJobActionCommand(
 		Long:       longDesc + "\n\nWhen only one job is managed, the job name can be omitted.",
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			apiClient := cli.NewApiClient(apiAddress)
+			apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
 
 			job, err := determineJobName(args, apiClient)
 			if err != nil {
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mittwald/mittnite/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	jobCreateCommand.Flags().StringP("file", "f", "", "JSON file containing the job configuration; use \"-\" to read from stdin")
+	_ = jobCreateCommand.MarkFlagRequired("file")
+
+	jobCommand.AddCommand(jobCreateCommand)
+	jobCommand.AddCommand(jobDeleteCommand)
+}
+
+var jobCreateCommand = &cobra.Command{
+	Use:   "create -f <file>",
+	Args:  cobra.NoArgs,
+	Short: "Create a job",
+	Long: "This command can be used to create and start a job at runtime, without changing the configuration directory.\n\n" +
+		"The job configuration is read from a JSON file, using the same keys as the HCL configuration. Jobs created this way are always controllable.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+
+		var (
+			jobConfig []byte
+			err       error
+		)
+		if file == "-" {
+			jobConfig, err = io.ReadAll(os.Stdin)
+		} else {
+			jobConfig, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read job configuration: %w", err)
+		}
+
+		var job struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(jobConfig, &job); err != nil {
+			return fmt.Errorf("failed to parse job configuration: %w", err)
+		}
+
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
+		if err := apiClient.JobCreate(jobConfig).Print(); err != nil {
+			return err
+		}
+
+		fmt.Println(styleSuccessBox.Render(
+			fmt.Sprintf("🐣 job %s created", styleHighlight.Render(job.Name)),
+		))
+		return nil
+	},
+}
+
+var jobDeleteCommand = &cobra.Command{
+	Use:        "delete <job>",
+	Args:       cobra.ExactArgs(1),
+	ArgAliases: []string{"job"},
+	Short:      "Delete a job",
+	Long: "This command can be used to stop a job and remove it from the managed jobs.\n\n" +
+		"Jobs from the configuration directory are added again when the configuration is reloaded.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		job := args[0]
+
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
+		if err := apiClient.JobDelete(job).Print(); err != nil {
+			return err
+		}
+
+		fmt.Println(styleSuccessBox.Render(
+			fmt.Sprintf("🗑️  job %s deleted", styleHighlight.Render(job)),
+		))
+		return nil
+	},
+}
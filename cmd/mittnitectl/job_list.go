@@ -19,7 +19,7 @@ var jobListCommand = &cobra.Command{
 	Short: "List jobs",
 	Long:  "This command can be used to list all managed jobs.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiClient := cli.NewApiClient(apiAddress)
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
 
 		resp := apiClient.JobList()
 		if resp.Err() != nil {
@@ -41,7 +41,7 @@ var jobListCommand = &cobra.Command{
 				return fmt.Errorf("failed to get status of job %s: %w", job, status.Err())
 			}
 
-			fmt.Println(styleListItem.Render(jobStatusLine(job, status.Body)))
+			fmt.Println(styleListItem.Render(jobStatusLine(job, &status.Body)))
 		}
 
 		fmt.Println(styleInfoBox.Render(
@@ -24,7 +24,7 @@ var jobLogsCommand = &cobra.Command{
 	Short:      "Get logs from job",
 	Long:       "This command can be used to get the logs of a managed job.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiClient := cli.NewApiClient(apiAddress)
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
 
 		job, err := determineJobName(args, apiClient)
 		if err != nil {
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mittwald/mittnite/internal/helper"
+	"github.com/mittwald/mittnite/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	jobSignalCommand.Flags().BoolP("group", "g", false, "send the signal to the whole process group of the job")
+
+	jobCommand.AddCommand(jobSignalCommand)
+}
+
+var jobSignalCommand = &cobra.Command{
+	Use:   "signal [--group] <job> <signal>",
+	Args:  cobra.RangeArgs(1, 2),
+	Short: "Send a signal to a job",
+	Long: "This command can be used to send a signal (like SIGHUP, HUP or 1) to the process of a managed job, e.g. to make it reload its configuration.\n\n" +
+		"When only one job is managed, the job name can be omitted.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
+
+		signal := args[len(args)-1]
+		if _, err := helper.ParseSignal(signal); err != nil {
+			return err
+		}
+
+		job, err := determineJobName(args[:len(args)-1], apiClient)
+		if err != nil {
+			return err
+		}
+
+		group, _ := cmd.Flags().GetBool("group")
+
+		fmt.Printf("📨 sending signal %s to job %s\n", styleHighlight.Render(signal), styleHighlight.Render(job))
+
+		if err := apiClient.JobSignal(job, signal, group).Print(); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mittwald/mittnite/pkg/cli"
+	"github.com/mittwald/mittnite/pkg/proc"
 	"github.com/spf13/cobra"
 	"os"
+	"time"
 )
 
 func init() {
@@ -29,7 +31,7 @@ var jobStatusCmd = cobra.Command{
 	Long:       "This command can be used to show the status of a managed job.\n\nWhen only one job is managed, the job name can be omitted.",
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		apiClient := cli.NewApiClient(apiAddress)
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
 
 		job, err := determineJobName(args, apiClient)
 		if err != nil {
@@ -48,7 +50,7 @@ var jobStatusCmd = cobra.Command{
 				return fmt.Errorf("failed to print output: %w", err)
 			}
 		} else {
-			fmt.Println(styleStatusMainLine.Render(jobStatusLine(job, resp.Body)))
+			fmt.Println(styleStatusMainLine.Render(jobStatusLine(job, &resp.Body)))
 			fmt.Println(styleStatusDetails.Render(lipgloss.JoinVertical(lipgloss.Left,
 				lipgloss.JoinHorizontal(
 					lipgloss.Left,
@@ -73,6 +75,12 @@ var jobStatusCmd = cobra.Command{
 					styleStatusLeftColumn.Render("stderr log file:"),
 					wrapNotSet(resp.Body.Config.Stderr),
 				),
+				lipgloss.JoinHorizontal(
+					lipgloss.Left,
+					styleStatusLeftColumn.Render("last restart reason:"),
+					wrapNotSet(resp.Body.LastRestartReason),
+				),
+				scheduleStatusLines(resp.Body.Schedule),
 			)))
 		}
 
@@ -101,6 +109,35 @@ var jobStatusCmd = cobra.Command{
 	},
 }
 
+func scheduleStatusLines(schedule *proc.ScheduleStatus) string {
+	if schedule == nil {
+		return ""
+	}
+
+	lastRun := styleNotSet.Render("<never>")
+	if schedule.LastRun != nil {
+		result := "succeeded"
+		if !schedule.LastRun.Success {
+			result = fmt.Sprintf("failed: %s", schedule.LastRun.Error)
+		}
+
+		lastRun = lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			styleHighlight.Render(schedule.LastRun.StartedAt.Format(time.RFC3339)),
+			styleStatusAddendum.Render("("+result+"; exit code: "), styleHighlight.Render(fmt.Sprintf("%d", schedule.LastRun.ExitCode)), ")",
+		)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			styleStatusLeftColumn.Render("next run:"),
+			styleHighlight.Render(schedule.NextRun.Format(time.RFC3339)),
+		),
+		lipgloss.JoinHorizontal(lipgloss.Left, styleStatusLeftColumn.Render("last run:"), lastRun),
+	)
+}
+
 func wrapNotSet(s string) string {
 	if s == "" {
 		return styleNotSet.Render("<not set>")
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	ctlCommand.AddCommand(probeCommand)
+}
+
+var probeCommand = &cobra.Command{
+	Use:   "probe",
+	Short: "Inspect and execute probes via command line",
+	Long:  "This command can be used to inspect and execute the configured probes.",
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mittwald/mittnite/pkg/cli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	probeListCommand.Flags().BoolP("json", "j", false, "output as JSON")
+
+	probeCommand.AddCommand(probeListCommand)
+}
+
+var probeListCommand = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.NoArgs,
+	Short: "List probes",
+	Long:  "This command can be used to execute all configured probes and list their results.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
+
+		resp := apiClient.ProbeList()
+		if resp.Err() != nil {
+			return resp.Err()
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if err := resp.Print(); err != nil {
+				log.Errorf("failed to print output: %s", err.Error())
+			}
+			return nil
+		}
+
+		if len(resp.Body) == 0 {
+			fmt.Print("There are no probes configured.\n\n")
+			return nil
+		}
+
+		fmt.Print("The following probes are configured:\n\n")
+
+		names := make([]string, 0, len(resp.Body))
+		for name := range resp.Body {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Println(styleListItem.Render(probeStatusLine(name, resp.Body[name])))
+		}
+
+		fmt.Println(styleInfoBox.Render(
+			lipgloss.JoinVertical(
+				lipgloss.Left,
+				"To inspect one of these probes, you can use the following commands:",
+				styleCommandBlock.Render(lipgloss.JoinVertical(lipgloss.Left,
+					styleCommand.Render(cmd.Parent().CommandPath()+" status")+styleParam.Render(" <probe>"),
+					styleCommand.Render(cmd.Parent().CommandPath()+" check")+styleParam.Render(" <probe>"),
+				)),
+				"Visit https://github.com/mittwald/mittnite to learn more about using the mittnite init system.",
+			),
+		))
+
+		return nil
+	},
+}
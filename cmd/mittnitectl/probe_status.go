@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mittwald/mittnite/pkg/cli"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	probeStatusCommand.Flags().BoolP("json", "j", false, "Print probe status as JSON")
+
+	probeCommand.AddCommand(probeStatusCommand, probeCheckCommand)
+}
+
+var probeStatusCommand = &cobra.Command{
+	Use:        "status <probe>",
+	Args:       cobra.ExactArgs(1),
+	ArgAliases: []string{"probe"},
+	Short:      "Show probe status",
+	Long:       "This command can be used to execute a probe and show its result in detail.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
+		name := args[0]
+
+		resp := apiClient.ProbeStatus(name)
+		if resp.Err() != nil {
+			return fmt.Errorf("failed to get status of probe %s: %w", name, resp.Err())
+		}
+
+		if printJson, _ := cmd.Flags().GetBool("json"); printJson {
+			if err := resp.Print(); err != nil {
+				return fmt.Errorf("failed to print output: %w", err)
+			}
+			return nil
+		}
+
+		result := "healthy"
+		if !resp.Body.OK {
+			result = "failing"
+		}
+
+		fmt.Println(styleStatusMainLine.Render(probeStatusLine(name, &resp.Body)))
+		fmt.Println(styleStatusDetails.Render(lipgloss.JoinVertical(lipgloss.Left,
+			lipgloss.JoinHorizontal(lipgloss.Left, styleStatusLeftColumn.Render("result:"), styleHighlight.Render(result)),
+			lipgloss.JoinHorizontal(lipgloss.Left, styleStatusLeftColumn.Render("latency:"), styleHighlight.Render(resp.Body.Latency.Round(time.Microsecond).String())),
+			lipgloss.JoinHorizontal(lipgloss.Left, styleStatusLeftColumn.Render("message:"), wrapNotSet(resp.Body.Message)),
+		)))
+
+		fmt.Println(styleInfoBox.Render(
+			lipgloss.JoinVertical(
+				lipgloss.Left,
+				"To use the result of this probe in a script, you can use the following command:",
+				styleCommandBlock.Render(lipgloss.JoinVertical(lipgloss.Left,
+					styleCommand.Render(cmd.Parent().CommandPath()+" check")+styleParam.Render(" "+name),
+				)),
+				"Visit https://github.com/mittwald/mittnite to learn more about using the mittnite init system.",
+			),
+		))
+
+		return nil
+	},
+}
+
+var probeCheckCommand = &cobra.Command{
+	Use:        "check <probe>",
+	Args:       cobra.ExactArgs(1),
+	ArgAliases: []string{"probe"},
+	Short:      "Execute a probe",
+	Long:       "This command can be used to execute a probe. It exits with status code 0 if the probe is healthy, 1 if not.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient := cli.NewApiClient(apiAddress, apiToken, apiTLSConfig)
+		name := args[0]
+
+		resp := apiClient.ProbeStatus(name)
+		if resp.Err() != nil {
+			return fmt.Errorf("failed to execute probe %s: %w", name, resp.Err())
+		}
+
+		fmt.Println(probeStatusLine(name, &resp.Body))
+		if !resp.Body.OK {
+			if resp.Body.Message != "" {
+				fmt.Println(styleStatusDetails.Render(resp.Body.Message))
+			}
+			os.Exit(1)
+		}
+
+		return nil
+	},
+}
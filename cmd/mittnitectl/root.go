@@ -1,18 +1,29 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/mittwald/mittnite/cmd"
+	"github.com/mittwald/mittnite/pkg/cli"
 	"github.com/spf13/cobra"
 	"os"
 )
 
 var (
-	apiAddress string
+	apiAddress   string
+	apiToken     string
+	apiTLSCert   string
+	apiTLSKey    string
+	apiCA        string
+	apiTLSConfig *tls.Config
 )
 
 func init() {
 	ctlCommand.PersistentFlags().StringVarP(&apiAddress, "api-address", "", cmd.DefaultAPIAddress, "write mittnites process id to this file")
+	ctlCommand.PersistentFlags().StringVarP(&apiToken, "api-token", "", "", "token to authenticate at the api; defaults to $MITTNITE_API_TOKEN")
+	ctlCommand.PersistentFlags().StringVarP(&apiTLSCert, "api-tls-cert", "", "", "client certificate file for https api addresses that require mutual TLS")
+	ctlCommand.PersistentFlags().StringVarP(&apiTLSKey, "api-tls-key", "", "", "private key file for --api-tls-cert")
+	ctlCommand.PersistentFlags().StringVarP(&apiCA, "api-ca", "", "", "CA file for verifying the certificate of https api addresses")
 	ctlCommand.AddCommand(cmd.VersionCmd)
 }
 
@@ -22,6 +33,16 @@ var ctlCommand = &cobra.Command{
 	Long:          "This command can be used to control mittnite by command line.",
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+		// not used as flag default, so that the token is not shown in --help
+		if apiToken == "" {
+			apiToken = os.Getenv("MITTNITE_API_TOKEN")
+		}
+
+		var err error
+		apiTLSConfig, err = cli.LoadTLSConfig(apiTLSCert, apiTLSKey, apiCA)
+		return err
+	},
 }
 
 func Execute() {
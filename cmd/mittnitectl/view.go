@@ -3,10 +3,12 @@ package main
 import (
 	"fmt"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mittwald/mittnite/pkg/probe"
 	"github.com/mittwald/mittnite/pkg/proc"
+	"time"
 )
 
-func jobStatusLine(job string, status proc.CommonJobStatus) string {
+func jobStatusLine(job string, status *proc.CommonJobStatus) string {
 	if status.Running {
 		return lipgloss.JoinHorizontal(lipgloss.Left,
 			styleRunning.Render("▶︎"), " ",
@@ -15,6 +17,13 @@ func jobStatusLine(job string, status proc.CommonJobStatus) string {
 			styleHighlight.Render(string(status.Phase.Reason)), "; pid=",
 			styleHighlight.Render(fmt.Sprintf("%d", status.Pid)), ")",
 		)
+	} else if status.Phase.Reason == proc.JobPhaseReasonScheduled && status.Schedule != nil {
+		return lipgloss.JoinHorizontal(lipgloss.Left,
+			styleStopped.Render("◷"), " ",
+			styleHighlight.Render(job), " (",
+			styleStopped.Render("scheduled"), "; next run=",
+			styleHighlight.Render(status.Schedule.NextRun.Format(time.RFC3339)), ")",
+		)
 	} else if status.Phase.Reason == proc.JobPhaseReasonStopped {
 		return lipgloss.JoinHorizontal(lipgloss.Left,
 			styleStopped.Render("◼︎"), " ",
@@ -30,3 +39,21 @@ func jobStatusLine(job string, status proc.CommonJobStatus) string {
 		)
 	}
 }
+
+func probeStatusLine(name string, result *probe.ProbeResult) string {
+	if result.OK {
+		return lipgloss.JoinHorizontal(lipgloss.Left,
+			styleRunning.Render("✔︎"), " ",
+			styleHighlight.Render(name), " (",
+			styleRunning.Render("healthy"), "; latency=",
+			styleHighlight.Render(result.Latency.Round(time.Microsecond).String()), ")",
+		)
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Left,
+		styleFailed.Render("✘"), " ",
+		styleHighlight.Render(name), " (",
+		styleFailed.Render("failing"), "; latency=",
+		styleHighlight.Render(result.Latency.Round(time.Microsecond).String()), ")",
+	)
+}
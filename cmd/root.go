@@ -1,20 +1,59 @@
 package cmd
 
 import (
+	"fmt"
 	"net"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var configDir string
 var enableProfile bool
+var logFormat string
+var logLevel string
 
 func init() {
+	defaultLogLevel := "info"
+	if level := os.Getenv("MITTNITE_LOG_LEVEL"); level != "" {
+		defaultLogLevel = level
+	}
+
 	rootCmd.PersistentFlags().StringVarP(&configDir, "config-dir", "c", "/etc/mittnite.d", "set directory to where your .hcl-configs are located")
 	rootCmd.PersistentFlags().BoolVar(&enableProfile, "profile", false, "enable pprof http server")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "format of mittnite's log messages (text or json)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", defaultLogLevel, "minimum level of mittnite's log messages (trace, debug, info, warn, error, fatal or panic); defaults to $MITTNITE_LOG_LEVEL if set")
+}
+
+// configureLogging configures the standard logger according to the
+// --log-format and --log-level flags. An invalid log level is only an error
+// if it has been passed explicitly; an invalid $MITTNITE_LOG_LEVEL is ignored.
+func configureLogging(flags *pflag.FlagSet) error {
+	switch logFormat {
+	case "text":
+		// the text formatter is configured in main
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	default:
+		return fmt.Errorf("invalid log format %q; must be one of \"text\" or \"json\"", logFormat)
+	}
+
+	level, err := log.ParseLevel(logLevel)
+	if err != nil {
+		if flags.Changed("log-level") {
+			return fmt.Errorf("invalid log level: %w", err)
+		}
+		log.WithError(err).Warn("ignoring invalid $MITTNITE_LOG_LEVEL, using log level info")
+		level = log.InfoLevel
+	}
+	log.SetLevel(level)
+
+	return nil
 }
 
 var rootCmd = &cobra.Command{
@@ -22,7 +61,11 @@ var rootCmd = &cobra.Command{
 	Short:   "Mittnite - Smart init system for containers",
 	Long:    "Mittnite is a small, but smart init system designed for usage as `ENTRYPOINT` in container images",
 	Version: Version,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := configureLogging(cmd.Flags()); err != nil {
+			return err
+		}
+
 		if enableProfile {
 			go func() {
 				// pprof handlers are auto-registered on the default ServeMux when imported.
@@ -37,6 +80,8 @@ var rootCmd = &cobra.Command{
 				}
 			}()
 		}
+
+		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Warn("Running 'mittnite' without any arguments - defaulting to 'up'. This behaviour may change in future releases!")
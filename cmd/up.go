@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/mittwald/mittnite/internal/config"
@@ -12,6 +13,7 @@ import (
 	"github.com/mittwald/mittnite/pkg/pidfile"
 	"github.com/mittwald/mittnite/pkg/probe"
 	"github.com/mittwald/mittnite/pkg/proc"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -25,6 +27,11 @@ var (
 	pidFile          string
 	apiEnabled       bool
 	apiListenAddress string
+	apiTokenFile     string
+	apiTLSCert       string
+	apiTLSKey        string
+	apiClientCA      string
+	apiJobCreation   bool
 	keepRunning      bool
 )
 
@@ -40,6 +47,11 @@ func init() {
 	up.PersistentFlags().StringVarP(&pidFile, "pidfile", "", "", "write mittnites process id to this file")
 	up.PersistentFlags().BoolVarP(&apiEnabled, "api", "", false, "enables the api for remote or cli controlling")
 	up.PersistentFlags().StringVarP(&apiListenAddress, "api-listen-address", "", DefaultAPIAddress, fmt.Sprintf("listen address for the api. Defaults to %q", DefaultAPIAddress))
+	up.PersistentFlags().StringVarP(&apiTokenFile, "api-token-file", "", "", "file containing the tokens that are accepted by the api, one \"<scope>:<token>\" per line; tokens can also be set in $MITTNITE_API_TOKENS")
+	up.PersistentFlags().StringVarP(&apiTLSCert, "api-tls-cert", "", "", "certificate file for serving the api via TLS (only when listening on a tcp port)")
+	up.PersistentFlags().StringVarP(&apiTLSKey, "api-tls-key", "", "", "private key file for --api-tls-cert")
+	up.PersistentFlags().StringVarP(&apiClientCA, "api-client-ca", "", "", "CA file for verifying client certificates; if set, clients must present a certificate (mutual TLS)")
+	up.PersistentFlags().BoolVarP(&apiJobCreation, "api-allow-job-creation", "", false, "allow creating jobs via api; requires tokens or mutual TLS when listening on a tcp port")
 	up.PersistentFlags().BoolVarP(&keepRunning, "keep-running", "k", false, "keep mittnite running even if no job is running anymore")
 }
 
@@ -54,6 +66,12 @@ var up = &cobra.Command{
 			Jobs:   nil,
 		}
 
+		// the tokens must not be inherited by jobs, hooks and probes
+		envTokens := os.Getenv("MITTNITE_API_TOKENS")
+		if err := os.Unsetenv("MITTNITE_API_TOKENS"); err != nil {
+			return fmt.Errorf("failed to unset $MITTNITE_API_TOKENS: %w", err)
+		}
+
 		pidFileHandle := pidfile.New(pidFile)
 
 		if err := pidFileHandle.Acquire(); err != nil {
@@ -74,12 +92,15 @@ var up = &cobra.Command{
 			return fmt.Errorf("failed while rendering files from ignition config, err: %w", err)
 		}
 
-		signals := make(chan os.Signal)
+		signals := make(chan os.Signal, 1)
 		signal.Notify(signals,
 			syscall.SIGTERM,
 			syscall.SIGINT,
 		)
 
+		reloadSignals := make(chan os.Signal, 1)
+		signal.Notify(reloadSignals, syscall.SIGHUP)
+
 		readinessSignals := make(chan os.Signal, 1)
 		probeSignals := make(chan os.Signal, 1)
 		procSignals := make(chan os.Signal, 1)
@@ -93,17 +114,10 @@ var up = &cobra.Command{
 			}
 		}()
 
-		probeHandler, _ := probe.NewProbeHandler(ignitionConfig)
-
-		go func() {
-			log.Infof("probeServer listens on port %d", probeListenPort)
-
-			if err := probe.RunProbeServer(probeHandler, probeSignals, probeListenPort); err != nil {
-				log.Fatalf("probe server stopped with error: %s", err)
-			} else {
-				log.Info("probe server stopped without error")
-			}
-		}()
+		probeHandler, err := probe.NewProbeHandler(ignitionConfig)
+		if err != nil {
+			return fmt.Errorf("failed to initialize probes: %w", err)
+		}
 
 		go proc.ReapChildren()
 
@@ -114,15 +128,70 @@ var up = &cobra.Command{
 		if apiEnabled {
 			api = proc.NewApi(apiListenAddress)
 
+			if apiTLSCert != "" || apiTLSKey != "" || apiClientCA != "" {
+				if apiTLSCert == "" || apiTLSKey == "" {
+					return fmt.Errorf("--api-tls-cert and --api-tls-key must be set to use TLS for the api")
+				}
+				if err := api.EnableTLS(apiTLSCert, apiTLSKey, apiClientCA); err != nil {
+					return fmt.Errorf("failed to configure TLS for the api: %w", err)
+				}
+			}
+
+			tokens, err := loadAPITokens(envTokens)
+			if err != nil {
+				return fmt.Errorf("failed to load api tokens: %w", err)
+			}
+
+			unauthenticated := len(tokens) == 0 && !strings.HasPrefix(apiListenAddress, "unix://") && apiClientCA == ""
+			if len(tokens) > 0 {
+				api.RegisterMiddlewareFuncs(proc.TokenAuthMiddleware(tokens))
+			} else if unauthenticated {
+				log.Warn("remote api listens on a tcp port without authentication; use --api-token-file or $MITTNITE_API_TOKENS to require tokens")
+			}
+
+			if apiJobCreation {
+				if unauthenticated {
+					return fmt.Errorf("--api-allow-job-creation requires api tokens or --api-client-ca when listening on a tcp port")
+				}
+				api.AllowJobCreation()
+			}
+
 			defer api.Shutdown()
 		}
 
 		runner := proc.NewRunner(ctx, api, keepRunning, ignitionConfig)
+		probe.OnStateChange(runner.OnProbeStateChange)
 
 		if err := runner.Init(); err != nil {
 			return fmt.Errorf("runner failed to initialize: %w", err)
 		}
 
+		probeHandler.SetJobReadinessSource(runner)
+		runner.SetProbeChecker(probeHandler)
+		prometheus.MustRegister(runner)
+		runner.SetConfigDir(configDir)
+
+		// start the probe server only after the state change callback has been
+		// registered, so that no state changes of probes are missed
+		go func() {
+			log.Infof("probeServer listens on port %d", probeListenPort)
+
+			if err := probe.RunProbeServer(probeHandler, probeSignals, probeListenPort); err != nil {
+				log.Fatalf("probe server stopped with error: %s", err)
+			} else {
+				log.Info("probe server stopped without error")
+			}
+		}()
+
+		go func() {
+			for s := range reloadSignals {
+				log.Infof("received event %s, reloading configuration", s.String())
+				if err := runner.Reload(); err != nil {
+					log.WithError(err).Error("failed to reload configuration")
+				}
+			}
+		}()
+
 		go func() {
 			// start the API BEFORE waiting for readiness signals, so that the API is available
 			// even if we're still waiting on some probes to become ready
@@ -155,3 +224,32 @@ var up = &cobra.Command{
 		return nil
 	},
 }
+
+// loadAPITokens reads the api tokens from --api-token-file and the given
+// contents of $MITTNITE_API_TOKENS.
+func loadAPITokens(env string) ([]proc.APIToken, error) {
+	var tokens []proc.APIToken
+
+	if apiTokenFile != "" {
+		content, err := os.ReadFile(apiTokenFile)
+		if err != nil {
+			return nil, err
+		}
+
+		fileTokens, err := proc.ParseAPITokens(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid token file %q: %w", apiTokenFile, err)
+		}
+		tokens = append(tokens, fileTokens...)
+	}
+
+	if env != "" {
+		envTokens, err := proc.ParseAPITokens(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $MITTNITE_API_TOKENS: %w", err)
+		}
+		tokens = append(tokens, envTokens...)
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/mittwald/mittnite/internal/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(validate)
+}
+
+var validate = &cobra.Command{
+	Use:   "validate",
+	Short: "Validates the configuration files",
+	Long:  "This command parses the configuration files and checks them for errors, without starting anything",
+	Run: func(cmd *cobra.Command, args []string) {
+		validationErrors, err := config.ValidateConfigDir(configDir)
+		if err != nil {
+			log.Errorf("failed while trying to read config dir '%s': %s", configDir, err)
+			os.Exit(1)
+		}
+
+		for _, validationErr := range validationErrors {
+			log.Error(validationErr.Error())
+		}
+
+		if len(validationErrors) > 0 {
+			log.Errorf("found %d error(s) in configuration dir '%s'", len(validationErrors), configDir)
+			os.Exit(1)
+		}
+
+		log.Infof("configuration in '%s' is valid", configDir)
+	},
+}
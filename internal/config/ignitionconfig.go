@@ -34,7 +34,8 @@ func (ignitionConfig *Ignition) GenerateFromConfigDir(configDir string) error {
 		}
 	}
 
-	for _, job := range ignitionConfig.Jobs {
+	for i := range ignitionConfig.Jobs {
+		job := &ignitionConfig.Jobs[i]
 		if job.MaxAttempts_ != nil {
 			log.Warnf("field max_attempts in job %s is deprecated in favor of maxAttempts", job.Name)
 			job.MaxAttempts = job.MaxAttempts_
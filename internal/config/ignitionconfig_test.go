@@ -0,0 +1,26 @@
+package config_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecatedMaxAttemptsIsApplied(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "jobs.hcl"), []byte(`job "web" {
+  command = "/bin/web"
+  max_attempts = 5
+}
+`), 0o644))
+
+	ignitionConfig := config.Ignition{}
+	require.NoError(t, ignitionConfig.GenerateFromConfigDir(dir))
+
+	require.Len(t, ignitionConfig.Jobs, 1)
+	assert.Equal(t, 5, ignitionConfig.Jobs[0].GetMaxAttempts())
+}
@@ -1,5 +1,7 @@
 package config
 
+import "net/url"
+
 type Credentials struct {
 	User     string
 	Password string
@@ -17,6 +19,15 @@ type MySQL struct {
 	Database            string
 }
 
+type Postgres struct {
+	Credentials
+	Host
+	Database string
+	SSLMode  string // defaults to "prefer"
+	Query    string // defaults to "SELECT 1"
+	Timeout  string // defaults to 5s
+}
+
 type Amqp struct {
 	Credentials
 	Host
@@ -50,16 +61,123 @@ type HttpGet struct {
 	Timeout string
 }
 
-type Probe struct {
-	Name       string `hcl:",key"`
-	Wait       bool
+// TCP checks that a connection to the given host can be established.
+type TCP struct {
+	Host
+	Timeout string // defaults to 5s
+}
+
+// UDP sends a payload to the given host and optionally waits for a reply.
+type UDP struct {
+	Host
+	Payload     string
+	ExpectReply bool
+	Reply       string // if set, the reply needs to contain this string; implies ExpectReply
+	Timeout     string // defaults to 5s
+}
+
+// Exec runs a command that is considered healthy if it exits with status 0.
+type Exec struct {
+	Command string
+	Args    []string
+	Env     []string
+	Timeout string // defaults to 5s
+}
+
+// ProbeBackends contains the supported probe types; exactly one of them is
+// expected to be configured.
+type ProbeBackends struct {
 	Filesystem string
 	MySQL      *MySQL
+	Postgres   *Postgres
 	Redis      *Redis
 	MongoDB    *MongoDB
 	Amqp       *Amqp
 	HTTP       *HttpGet
 	SMTP       *SMTP
+	TCP        *TCP
+	UDP        *UDP
+	Exec       *Exec
+}
+
+// IsConfigured returns true if at least one probe backend is configured.
+func (b *ProbeBackends) IsConfigured() bool {
+	return b.Filesystem != "" ||
+		b.MySQL != nil ||
+		b.Postgres != nil ||
+		b.Redis != nil ||
+		b.MongoDB != nil ||
+		b.Amqp != nil ||
+		b.HTTP != nil ||
+		b.SMTP != nil ||
+		b.TCP != nil ||
+		b.UDP != nil ||
+		b.Exec != nil
+}
+
+// redactedValue replaces secrets in configurations that are exposed via api.
+const redactedValue = "REDACTED"
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// Redacted returns a copy of the probe backends in which passwords are
+// replaced, so that they can be exposed via api.
+func (b ProbeBackends) Redacted() ProbeBackends {
+	if b.MySQL != nil {
+		c := *b.MySQL
+		c.Password = redact(c.Password)
+		b.MySQL = &c
+	}
+	if b.Postgres != nil {
+		c := *b.Postgres
+		c.Password = redact(c.Password)
+		b.Postgres = &c
+	}
+	if b.Redis != nil {
+		c := *b.Redis
+		c.Password = redact(c.Password)
+		b.Redis = &c
+	}
+	if b.MongoDB != nil {
+		c := *b.MongoDB
+		c.Password = redact(c.Password)
+		if u, err := url.Parse(c.URL); err == nil && u.User != nil {
+			c.URL = u.Redacted()
+		}
+		b.MongoDB = &c
+	}
+	if b.Amqp != nil {
+		c := *b.Amqp
+		c.Password = redact(c.Password)
+		b.Amqp = &c
+	}
+	return b
+}
+
+type Probe struct {
+	Name          string `hcl:",key"`
+	Wait          bool
+	ProbeBackends `hcl:",squash"`
+}
+
+// Readiness configures a check that needs to pass before a started job is
+// considered ready.
+type Readiness struct {
+	ProbeBackends `hcl:",squash" json:",inline"`
+	Interval      string `hcl:"interval" json:"interval,omitempty"` // defaults to 1s
+}
+
+// Liveness configures a check that is executed periodically while a job is
+// running; the job is restarted when the check fails repeatedly.
+type Liveness struct {
+	ProbeBackends    `hcl:",squash" json:",inline"`
+	Interval         string `hcl:"interval" json:"interval,omitempty"`                 // defaults to 10s
+	FailureThreshold int    `hcl:"failureThreshold" json:"failureThreshold,omitempty"` // defaults to 3
 }
 
 type Watch struct {
@@ -72,9 +190,16 @@ type Watch struct {
 }
 
 type WatchCommand struct {
-	Command string   `hcl:"command"`
-	Args    []string `hcl:"args"`
-	Env     []string `hcl:"env"`
+	Command string   `hcl:"command" json:"command"`
+	Args    []string `hcl:"args" json:"args,omitempty"`
+	Env     []string `hcl:"env" json:"env,omitempty"`
+}
+
+// HookCommand is a command that is executed at a certain point of a job's
+// lifecycle.
+type HookCommand struct {
+	WatchCommand `hcl:",squash" json:",inline"`
+	Timeout      string `hcl:"timeout" json:"timeout,omitempty"` // defaults to 30s
 }
 
 type Listener struct {
@@ -95,12 +220,60 @@ type BaseJobConfig struct {
 	Controllable     bool     `hcl:"controllable" json:"controllable"`
 	WorkingDirectory string   `hcl:"workingDirectory" json:"workingDirectory,omitempty"`
 
+	// user and groups to run the process as (names or numeric ids)
+	User                string   `hcl:"user" json:"user,omitempty"`
+	Group               string   `hcl:"group" json:"group,omitempty"` // defaults to the user's primary group
+	SupplementaryGroups []string `hcl:"supplementaryGroups" json:"supplementaryGroups,omitempty"`
+
+	Limits *Limits `hcl:"limits" json:"limits,omitempty"`
+
+	// how the process is stopped; it is killed if it does not exit within
+	// stopTimeout after receiving stopSignal
+	StopSignal  string `hcl:"stopSignal" json:"stopSignal,omitempty"`   // defaults to SIGTERM
+	StopTimeout string `hcl:"stopTimeout" json:"stopTimeout,omitempty"` // defaults to 10s
+
 	// log config
-	Stdout                string `hcl:"stdout" json:"stdout,omitempty"`
-	Stderr                string `hcl:"stderr" json:"stderr,omitempty"`
-	EnableTimestamps      bool   `hcl:"enableTimestamps" json:"enableTimestamps"`
-	TimestampFormat       string `hcl:"timestampFormat" json:"timestampFormat"` // defaults to RFC3339
-	CustomTimestampFormat string `hcl:"customTimestampFormat" json:"customTimestampFormat"`
+	Stdout                string       `hcl:"stdout" json:"stdout,omitempty"`
+	Stderr                string       `hcl:"stderr" json:"stderr,omitempty"`
+	EnableTimestamps      bool         `hcl:"enableTimestamps" json:"enableTimestamps"`
+	TimestampFormat       string       `hcl:"timestampFormat" json:"timestampFormat"` // defaults to RFC3339
+	CustomTimestampFormat string       `hcl:"customTimestampFormat" json:"customTimestampFormat"`
+	LogRotation           *LogRotation `hcl:"logRotation" json:"logRotation,omitempty"`
+	LogFormat             string       `hcl:"logFormat" json:"logFormat,omitempty"` // raw (default), prefixed or json; only for output not written to a file
+}
+
+const (
+	LogFormatRaw      = "raw"
+	LogFormatPrefixed = "prefixed"
+	LogFormatJSON     = "json"
+)
+
+// ValidLogFormat reports whether format is a supported logFormat; an empty
+// format selects the default.
+func ValidLogFormat(format string) bool {
+	switch format {
+	case "", LogFormatRaw, LogFormatPrefixed, LogFormatJSON:
+		return true
+	}
+	return false
+}
+
+// LogRotation configures the rotation of a job's stdout and stderr files.
+type LogRotation struct {
+	MaxSize  string `hcl:"maxSize" json:"maxSize,omitempty"`   // e.g. "50MB"
+	MaxFiles int    `hcl:"maxFiles" json:"maxFiles,omitempty"` // all rotated files are kept by default
+	MaxAge   string `hcl:"maxAge" json:"maxAge,omitempty"`     // e.g. "7d"; rotated files are kept forever by default
+	Compress bool   `hcl:"compress" json:"compress,omitempty"`
+}
+
+// Limits configures resource limits for a job's process. Unset fields keep
+// the values inherited from mittnite; rlimits of -1 mean "unlimited".
+type Limits struct {
+	NoFile      *int `hcl:"nofile" json:"nofile,omitempty"`
+	NProc       *int `hcl:"nproc" json:"nproc,omitempty"`
+	Core        *int `hcl:"core" json:"core,omitempty"`
+	Nice        *int `hcl:"nice" json:"nice,omitempty"`               // -20 to 19
+	OOMScoreAdj *int `hcl:"oomScoreAdj" json:"oomScoreAdj,omitempty"` // -1000 to 1000
 }
 
 type Laziness struct {
@@ -108,21 +281,86 @@ type Laziness struct {
 	CoolDownTimeout string `hcl:"coolDownTimeout"`
 }
 
+const (
+	OverlapPolicySkip    = "skip"
+	OverlapPolicyQueue   = "queue"
+	OverlapPolicyReplace = "replace"
+)
+
+// Schedule configures a job to be run periodically instead of continuously.
+type Schedule struct {
+	Cron          string `hcl:"cron" json:"cron"`
+	OverlapPolicy string `hcl:"overlapPolicy" json:"overlapPolicy,omitempty"` // skip (default), queue or replace
+	Timeout       string `hcl:"timeout" json:"timeout,omitempty"`             // no timeout by default
+}
+
+const (
+	RestartPolicyAlways    = "always"
+	RestartPolicyOnFailure = "on-failure"
+	RestartPolicyNever     = "never"
+)
+
+// Restart configures whether and how a job is restarted after its process
+// exited.
+type Restart struct {
+	Policy         string  `hcl:"policy" json:"policy,omitempty"`                 // defaults to always (on-failure for one-time jobs)
+	InitialBackoff string  `hcl:"initialBackoff" json:"initialBackoff,omitempty"` // defaults to 1s
+	MaxBackoff     string  `hcl:"maxBackoff" json:"maxBackoff,omitempty"`         // defaults to 300s
+	Multiplier     float64 `hcl:"multiplier" json:"multiplier,omitempty"`         // defaults to 2
+	Jitter         float64 `hcl:"jitter" json:"jitter,omitempty"`                 // fraction of the backoff (0 to 1) by which it is randomly varied
+	ResetAfter     string  `hcl:"resetAfter" json:"resetAfter,omitempty"`         // defaults to the current backoff
+}
+
 type JobConfig struct {
 	BaseJobConfig `hcl:",squash" json:",inline"`
 
+	// names of jobs that need to be started (or completed, for one-time jobs)
+	// before this job is started
+	DependsOn []string `hcl:"dependsOn" json:"dependsOn,omitempty"`
+
 	// optional fields for "normal" jobs
 	// these will be ignored if fields for lazy jobs are set
-	Watches      []Watch `hcl:"watch" json:"watch"`
-	MaxAttempts_ *int    `hcl:"max_attempts" json:"-,omitempty"` // deprecated
-	MaxAttempts  *int    `hcl:"maxAttempts" json:"maxAttempts,omitempty"`
-	OneTime      bool    `hcl:"oneTime" json:"oneTime"`
+	Watches      []Watch  `hcl:"watch" json:"watch"`
+	MaxAttempts_ *int     `hcl:"max_attempts" json:"-,omitempty"` // deprecated
+	MaxAttempts  *int     `hcl:"maxAttempts" json:"maxAttempts,omitempty"`
+	OneTime      bool     `hcl:"oneTime" json:"oneTime"`
+	Restart      *Restart `hcl:"restart" json:"restart,omitempty"`
+
+	// commands that are executed around each start and stop of the process
+	PreStart  *HookCommand `hcl:"preStart" json:"preStart,omitempty"`
+	PostStart *HookCommand `hcl:"postStart" json:"postStart,omitempty"`
+	PreStop   *HookCommand `hcl:"preStop" json:"preStop,omitempty"`
+	PostStop  *HookCommand `hcl:"postStop" json:"postStop,omitempty"`
+
+	Readiness *Readiness `hcl:"readiness" json:"readiness,omitempty"`
+	Liveness  *Liveness  `hcl:"liveness" json:"liveness,omitempty"`
+
+	// if set, the job is run periodically instead of continuously
+	Schedule *Schedule `hcl:"schedule" json:"schedule,omitempty"`
 
 	// fields required for lazy activation
 	Laziness  *Laziness  `hcl:"lazy" json:"lazy"`
 	Listeners []Listener `hcl:"listen" json:"listen"`
 }
 
+// Redacted returns a copy of the job config in which secrets (like the
+// passwords of readiness and liveness checks) are replaced, so that it can be exposed via
+// api.
+func (jc *JobConfig) Redacted() *JobConfig {
+	c := *jc
+	if c.Readiness != nil {
+		readiness := *c.Readiness
+		readiness.ProbeBackends = readiness.ProbeBackends.Redacted()
+		c.Readiness = &readiness
+	}
+	if c.Liveness != nil {
+		liveness := *c.Liveness
+		liveness.ProbeBackends = liveness.ProbeBackends.Redacted()
+		c.Liveness = &liveness
+	}
+	return &c
+}
+
 func (jc *JobConfig) GetMaxAttempts() int {
 	maxAttempts := 3
 	if jc.MaxAttempts == nil {
@@ -0,0 +1,644 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/parser"
+	"github.com/mittwald/mittnite/internal/helper"
+	"github.com/robfig/cron/v3"
+)
+
+// ValidationError describes a problem found in a configuration file.
+type ValidationError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.File == "" {
+		return e.Message
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Message)
+}
+
+type definition struct {
+	file string
+	line int
+}
+
+func (d definition) String() string {
+	return fmt.Sprintf("%s:%d", d.file, d.line)
+}
+
+type dependency struct {
+	definition
+	job  string
+	name string
+}
+
+type validator struct {
+	file string
+	errs []*ValidationError
+
+	jobs         map[string]definition
+	bootJobs     map[string]definition
+	probes       map[string]definition
+	dependencies []dependency
+}
+
+// ValidateConfigDir parses all configuration files in the given directory and
+// checks them for errors, without applying the configuration in any way.
+func ValidateConfigDir(configDir string) ([]*ValidationError, error) {
+	matches, err := findInPath(strings.TrimRight(configDir, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator{
+		jobs:     make(map[string]definition),
+		bootJobs: make(map[string]definition),
+		probes:   make(map[string]definition),
+	}
+
+	for _, m := range matches {
+		contents, err := os.ReadFile(m)
+		if err != nil {
+			return nil, err
+		}
+
+		v.validateFile(m, contents)
+	}
+
+	for _, dep := range v.dependencies {
+		if _, ok := v.jobs[dep.name]; !ok {
+			v.file = dep.file
+			v.addf(dep.line, "job %q depends on unknown job %q", dep.job, dep.name)
+		}
+	}
+
+	v.checkDependencyCycles()
+
+	return v.errs, nil
+}
+
+// checkDependencyCycles reports every cycle in the dependencies between jobs
+// once, at the dependsOn of the job at which the cycle was found.
+func (v *validator) checkDependencyCycles() {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	graph := make(map[string][]dependency)
+	var jobs []string
+	for _, dep := range v.dependencies {
+		if _, ok := graph[dep.job]; !ok {
+			jobs = append(jobs, dep.job)
+		}
+		graph[dep.job] = append(graph[dep.job], dep)
+	}
+
+	state := make(map[string]int)
+	var path []dependency
+
+	var visit func(job string)
+	visit = func(job string) {
+		state[job] = visiting
+
+		for _, dep := range graph[job] {
+			switch state[dep.name] {
+			case visiting:
+				start := len(path)
+				for i := range path {
+					if path[i].job == dep.name {
+						start = i
+						break
+					}
+				}
+
+				cycle := append(append([]dependency{}, path[start:]...), dep)
+				names := make([]string, 0, len(cycle)+1)
+				for _, d := range cycle {
+					names = append(names, d.job)
+				}
+				names = append(names, dep.name)
+
+				v.file = cycle[0].file
+				v.addf(cycle[0].line, "dependency cycle detected: %s", strings.Join(names, " -> "))
+			case unvisited:
+				path = append(path, dep)
+				visit(dep.name)
+				path = path[:len(path)-1]
+			}
+		}
+
+		state[job] = visited
+	}
+
+	for _, job := range jobs {
+		if state[job] == unvisited {
+			visit(job)
+		}
+	}
+}
+
+// ValidateJob checks a single job configuration that has not been read from a
+// configuration file, like a job created via api. Dependencies are not
+// checked, as they refer to other jobs.
+func ValidateJob(c *JobConfig) []*ValidationError {
+	v := validator{
+		jobs: make(map[string]definition),
+	}
+	v.validateJob(nil, c)
+
+	return v.errs
+}
+
+func (v *validator) addf(line int, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{
+		File:    v.file,
+		Line:    line,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) addErr(err error) {
+	var posErr *parser.PosError
+	if errors.As(err, &posErr) {
+		v.addf(posErr.Pos.Line, "%s", posErr.Err.Error())
+		return
+	}
+	v.addf(0, "%s", err.Error())
+}
+
+func (v *validator) validateFile(file string, contents []byte) {
+	v.file = file
+
+	f, err := hcl.ParseBytes(contents)
+	if err != nil {
+		v.addErr(err)
+		return
+	}
+
+	root, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		v.addf(0, "unexpected file structure")
+		return
+	}
+
+	v.checkUnknownKeys(root, reflect.TypeOf(Ignition{}), "")
+
+	for _, item := range root.Items {
+		// decode each top-level item on its own, so that every configuration
+		// block can be mapped to its position in the file
+		var single Ignition
+		if err := hcl.DecodeObject(&single, &ast.ObjectList{Items: []*ast.ObjectItem{item}}); err != nil {
+			v.addErr(err)
+			continue
+		}
+
+		for i := range single.Jobs {
+			v.validateJob(item, &single.Jobs[i])
+		}
+
+		for i := range single.BootJobs {
+			v.validateBootJob(item, &single.BootJobs[i])
+		}
+
+		for i := range single.Probes {
+			v.validateProbe(item, &single.Probes[i])
+		}
+	}
+}
+
+func (v *validator) checkDuplicate(defs map[string]definition, kind, name string, line int) {
+	if previous, exists := defs[name]; exists {
+		v.addf(line, "duplicate %s %q (already defined at %s)", kind, name, previous)
+		return
+	}
+	defs[name] = definition{file: v.file, line: line}
+}
+
+func (v *validator) checkDuration(line int, value, field, context string) {
+	if value == "" || strings.HasPrefix(value, "ENV:") {
+		return
+	}
+	if _, err := time.ParseDuration(value); err != nil {
+		v.addf(line, "invalid duration %q for %s in %s: %s", value, field, context, err.Error())
+	}
+}
+
+func (v *validator) validateBaseJob(item *ast.ObjectItem, kind string, c *BaseJobConfig) {
+	context := fmt.Sprintf("%s %q", kind, c.Name)
+
+	if c.Command == "" {
+		v.addf(lineOf(item), "%s has no command", context)
+	}
+
+	if c.TimestampFormat != "" {
+		if _, ok := TimeLayouts[c.TimestampFormat]; !ok {
+			v.addf(lineOf(item, "timestampFormat"), "unknown timestampFormat %q in %s", c.TimestampFormat, context)
+		}
+	}
+
+	if !ValidLogFormat(c.LogFormat) {
+		v.addf(lineOf(item, "logFormat"), "unknown logFormat %q in %s; must be one of %q, %q or %q", c.LogFormat, context, LogFormatRaw, LogFormatPrefixed, LogFormatJSON)
+	}
+
+	if c.Limits != nil {
+		v.validateLimits(item, context, c.Limits)
+	}
+
+	if r := c.LogRotation; r != nil {
+		rotationContext := "logRotation of " + context
+		if c.Stdout == "" && c.Stderr == "" {
+			v.addf(lineOf(item, "logRotation"), "%s has no effect, because neither stdout nor stderr is set", rotationContext)
+		}
+		if r.MaxSize != "" {
+			if _, err := helper.ParseByteSize(r.MaxSize); err != nil {
+				v.addf(lineOf(item, "logRotation", "maxSize"), "%s in %s", err.Error(), rotationContext)
+			}
+		}
+		if r.MaxAge != "" && !strings.HasPrefix(r.MaxAge, "ENV:") {
+			if _, err := helper.ParseDuration(r.MaxAge); err != nil {
+				v.addf(lineOf(item, "logRotation", "maxAge"), "invalid duration %q for maxAge in %s", r.MaxAge, rotationContext)
+			}
+		}
+		if r.MaxFiles < 0 {
+			v.addf(lineOf(item, "logRotation", "maxFiles"), "maxFiles in %s must not be negative", rotationContext)
+		}
+	}
+
+	if c.StopSignal != "" {
+		if _, err := helper.ParseSignal(c.StopSignal); err != nil {
+			v.addf(lineOf(item, "stopSignal"), "invalid stopSignal in %s: %s", context, err.Error())
+		}
+	}
+	v.checkDuration(lineOf(item, "stopTimeout"), c.StopTimeout, "stopTimeout", context)
+}
+
+func (v *validator) validateLimits(item *ast.ObjectItem, context string, l *Limits) {
+	checkRange := func(value *int, field string, lower, upper int) {
+		if value != nil && (*value < lower || *value > upper) {
+			v.addf(lineOf(item, "limits", field), "%s limit %d of %s is out of range (%d to %d)", field, *value, context, lower, upper)
+		}
+	}
+
+	rlimits := []struct {
+		field string
+		value *int
+	}{{"nofile", l.NoFile}, {"nproc", l.NProc}, {"core", l.Core}}
+
+	for _, r := range rlimits {
+		if r.value != nil && *r.value < -1 {
+			v.addf(lineOf(item, "limits", r.field), "%s limit %d of %s is invalid (use -1 for unlimited)", r.field, *r.value, context)
+		}
+	}
+
+	checkRange(l.Nice, "nice", -20, 19)
+	checkRange(l.OOMScoreAdj, "oomScoreAdj", -1000, 1000)
+}
+
+func (v *validator) validateJob(item *ast.ObjectItem, c *JobConfig) {
+	context := fmt.Sprintf("job %q", c.Name)
+
+	v.checkDuplicate(v.jobs, "job", c.Name, lineOf(item))
+	v.validateBaseJob(item, "job", &c.BaseJobConfig)
+
+	for _, name := range c.DependsOn {
+		v.dependencies = append(v.dependencies, dependency{
+			definition: definition{file: v.file, line: lineOf(item, "dependsOn")},
+			job:        c.Name,
+			name:       name,
+		})
+	}
+
+	for i := range c.Watches {
+		watch := &c.Watches[i]
+		line := lineOf(item, "watch", watch.Filename)
+		watchContext := fmt.Sprintf("watch %q of %s", watch.Filename, context)
+
+		if watch.Signal == 0 {
+			v.addf(line, "%s has no signal configured", watchContext)
+		}
+		if watch.PreCommand != nil && watch.PreCommand.Command == "" {
+			v.addf(lineOf(item, "watch", watch.Filename, "preCommand"), "preCommand of %s has no command", watchContext)
+		}
+		if watch.PostCommand != nil && watch.PostCommand.Command == "" {
+			v.addf(lineOf(item, "watch", watch.Filename, "postCommand"), "postCommand of %s has no command", watchContext)
+		}
+	}
+
+	if c.Laziness != nil {
+		v.checkDuration(lineOf(item, "lazy", "spinUpTimeout"), c.Laziness.SpinUpTimeout, "spinUpTimeout", context)
+		v.checkDuration(lineOf(item, "lazy", "coolDownTimeout"), c.Laziness.CoolDownTimeout, "coolDownTimeout", context)
+	}
+
+	if c.Readiness != nil {
+		v.validateProbeBackends(item, "readiness check of "+context, &c.Readiness.ProbeBackends, "readiness")
+		v.checkDuration(lineOf(item, "readiness", "interval"), c.Readiness.Interval, "interval", "readiness check of "+context)
+	}
+
+	if c.Liveness != nil {
+		v.validateProbeBackends(item, "liveness check of "+context, &c.Liveness.ProbeBackends, "liveness")
+		v.checkDuration(lineOf(item, "liveness", "interval"), c.Liveness.Interval, "interval", "liveness check of "+context)
+	}
+
+	hooks := []struct {
+		name string
+		hook *HookCommand
+	}{{"preStart", c.PreStart}, {"postStart", c.PostStart}, {"preStop", c.PreStop}, {"postStop", c.PostStop}}
+
+	for _, h := range hooks {
+		if h.hook == nil {
+			continue
+		}
+		hookContext := fmt.Sprintf("%s hook of %s", h.name, context)
+		if h.hook.Command == "" {
+			v.addf(lineOf(item, h.name), "%s has no command", hookContext)
+		}
+		v.checkDuration(lineOf(item, h.name, "timeout"), h.hook.Timeout, "timeout", hookContext)
+	}
+
+	if c.Restart != nil {
+		v.validateRestart(item, context, c)
+	}
+
+	if c.Schedule != nil {
+		v.validateSchedule(item, context, c)
+	}
+}
+
+func (v *validator) validateRestart(item *ast.ObjectItem, context string, c *JobConfig) {
+	r := c.Restart
+
+	switch r.Policy {
+	case "", RestartPolicyOnFailure, RestartPolicyNever:
+	case RestartPolicyAlways:
+		if c.OneTime {
+			v.addf(lineOf(item, "restart", "policy"), "restart policy %q cannot be used for one-time %s", r.Policy, context)
+		}
+	default:
+		v.addf(lineOf(item, "restart", "policy"), "unknown restart policy %q in %s", r.Policy, context)
+	}
+
+	restartContext := "restart configuration of " + context
+	v.checkDuration(lineOf(item, "restart", "initialBackoff"), r.InitialBackoff, "initialBackoff", restartContext)
+	v.checkDuration(lineOf(item, "restart", "maxBackoff"), r.MaxBackoff, "maxBackoff", restartContext)
+	for _, backoff := range []struct{ field, value string }{
+		{"initialBackoff", r.InitialBackoff},
+		{"maxBackoff", r.MaxBackoff},
+	} {
+		if d, err := time.ParseDuration(backoff.value); err == nil && d <= 0 {
+			v.addf(lineOf(item, "restart", backoff.field), "%s %q in %s must be greater than 0", backoff.field, backoff.value, restartContext)
+		}
+	}
+	v.checkDuration(lineOf(item, "restart", "resetAfter"), r.ResetAfter, "resetAfter", restartContext)
+
+	if r.Multiplier != 0 && r.Multiplier < 1 {
+		v.addf(lineOf(item, "restart", "multiplier"), "multiplier %v in %s must be at least 1", r.Multiplier, restartContext)
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		v.addf(lineOf(item, "restart", "jitter"), "jitter %v in %s must be between 0 and 1", r.Jitter, restartContext)
+	}
+}
+
+func (v *validator) validateSchedule(item *ast.ObjectItem, context string, c *JobConfig) {
+	s := c.Schedule
+
+	if s.Cron == "" {
+		v.addf(lineOf(item, "schedule"), "schedule of %s has no cron expression", context)
+	} else if _, err := cron.ParseStandard(s.Cron); err != nil {
+		v.addf(lineOf(item, "schedule", "cron"), "invalid cron expression %q in %s: %s", s.Cron, context, err.Error())
+	}
+
+	switch s.OverlapPolicy {
+	case "", OverlapPolicySkip, OverlapPolicyQueue, OverlapPolicyReplace:
+	default:
+		v.addf(lineOf(item, "schedule", "overlapPolicy"), "unknown overlapPolicy %q in %s", s.OverlapPolicy, context)
+	}
+
+	v.checkDuration(lineOf(item, "schedule", "timeout"), s.Timeout, "timeout", "schedule of "+context)
+
+	if c.OneTime || c.Laziness != nil {
+		v.addf(lineOf(item, "schedule"), "%s cannot be scheduled and oneTime or lazy at the same time", context)
+	}
+}
+
+func (v *validator) validateBootJob(item *ast.ObjectItem, c *BootJobConfig) {
+	v.checkDuplicate(v.bootJobs, "boot job", c.Name, item.Pos().Line)
+	v.validateBaseJob(item, "boot job", &c.BaseJobConfig)
+	v.checkDuration(lineOf(item, "timeout"), c.Timeout, "timeout", fmt.Sprintf("boot job %q", c.Name))
+}
+
+func (v *validator) validateProbe(item *ast.ObjectItem, c *Probe) {
+	v.checkDuplicate(v.probes, "probe", c.Name, item.Pos().Line)
+	v.validateProbeBackends(item, fmt.Sprintf("probe %q", c.Name), &c.ProbeBackends)
+}
+
+func (v *validator) validateProbeBackends(item *ast.ObjectItem, context string, b *ProbeBackends, keys ...string) {
+	if !b.IsConfigured() {
+		v.addf(lineOf(item, keys...), "%s configures no probe backend", context)
+	}
+
+	if b.HTTP != nil {
+		timeoutKeys := append(append([]string{}, keys...), "http", "timeout")
+		v.checkDuration(lineOf(item, timeoutKeys...), b.HTTP.Timeout, "timeout", context)
+	}
+
+	if b.Postgres != nil {
+		postgresKeys := append(append([]string{}, keys...), "postgres")
+		switch b.Postgres.SSLMode {
+		case "", "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			if !strings.HasPrefix(b.Postgres.SSLMode, "ENV:") {
+				v.addf(lineOf(item, append(postgresKeys, "sslMode")...), "unknown sslMode %q in %s", b.Postgres.SSLMode, context)
+			}
+		}
+		v.checkDuration(lineOf(item, append(postgresKeys, "timeout")...), b.Postgres.Timeout, "timeout", context)
+	}
+
+	if b.TCP != nil {
+		v.validateNetworkProbe(item, context, "tcp", b.TCP.Port, b.TCP.Timeout, keys)
+	}
+
+	if b.UDP != nil {
+		v.validateNetworkProbe(item, context, "udp", b.UDP.Port, b.UDP.Timeout, keys)
+	}
+
+	if b.Exec != nil {
+		execKeys := append(append([]string{}, keys...), "exec")
+		if b.Exec.Command == "" {
+			v.addf(lineOf(item, execKeys...), "exec probe of %s has no command", context)
+		}
+		v.checkDuration(lineOf(item, append(execKeys, "timeout")...), b.Exec.Timeout, "timeout", context)
+	}
+}
+
+func (v *validator) validateNetworkProbe(item *ast.ObjectItem, context, network, port, timeout string, keys []string) {
+	probeKeys := append(append([]string{}, keys...), network)
+	if port == "" {
+		v.addf(lineOf(item, probeKeys...), "%s probe of %s has no port configured", network, context)
+	}
+	v.checkDuration(lineOf(item, append(probeKeys, "timeout")...), timeout, "timeout", context)
+}
+
+// checkUnknownKeys reports all keys in the given list that do not correspond
+// to a field of the given struct type, and descends into nested blocks.
+func (v *validator) checkUnknownKeys(list *ast.ObjectList, t reflect.Type, context string) {
+	fields := hclFields(t)
+
+	for _, item := range list.Items {
+		if len(item.Keys) == 0 {
+			continue
+		}
+
+		key, _ := item.Keys[0].Token.Value().(string)
+		fieldType, ok := fields[strings.ToLower(key)]
+		if !ok {
+			if context == "" {
+				v.addf(item.Pos().Line, "unknown key %q", key)
+			} else {
+				v.addf(item.Pos().Line, "unknown key %q in %s", key, context)
+			}
+			continue
+		}
+
+		for fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Slice {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct {
+			continue
+		}
+
+		nestedContext := key
+		for _, label := range item.Keys[1:] {
+			nestedContext += fmt.Sprintf(" %q", label.Token.Value())
+		}
+		if context != "" {
+			nestedContext = context + " > " + nestedContext
+		}
+
+		for _, nested := range objectLists(item.Val) {
+			v.checkUnknownKeys(nested, fieldType, nestedContext)
+		}
+	}
+}
+
+// hclFields returns the types of all fields of the given struct type that can
+// be set from HCL, keyed by their lower-cased HCL key. It follows the same
+// rules as the HCL decoder.
+func hclFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tagParts := strings.Split(field.Tag.Get("hcl"), ",")
+		if tagParts[0] == "-" {
+			continue
+		}
+
+		isKey, isSquashed := false, false
+		for _, tag := range tagParts[1:] {
+			isKey = isKey || tag == "key"
+			isSquashed = isSquashed || tag == "squash"
+		}
+
+		switch {
+		case isKey:
+			continue
+		case field.Anonymous && isSquashed:
+			for name, fieldType := range hclFields(field.Type) {
+				fields[name] = fieldType
+			}
+			continue
+		}
+
+		name := field.Name
+		if tagParts[0] != "" {
+			name = tagParts[0]
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+
+	return fields
+}
+
+func objectLists(node ast.Node) []*ast.ObjectList {
+	switch n := node.(type) {
+	case *ast.ObjectType:
+		return []*ast.ObjectList{n.List}
+	case *ast.ListType:
+		var lists []*ast.ObjectList
+		for _, elem := range n.List {
+			lists = append(lists, objectLists(elem)...)
+		}
+		return lists
+	}
+	return nil
+}
+
+// lineOf returns the line of the nested item that is addressed by the given
+// keys (including block labels), or the line of the closest parent that could
+// be found. It returns 0 if there is no item, i.e. the configuration has not
+// been read from a file.
+func lineOf(item *ast.ObjectItem, keys ...string) int {
+	if item == nil {
+		return 0
+	}
+
+	line := item.Pos().Line
+	current := item
+
+	for len(keys) > 0 {
+		var next *ast.ObjectItem
+		consumed := 0
+
+	search:
+		for _, list := range objectLists(current.Val) {
+			for _, child := range list.Items {
+				if n := matchKeys(child, keys); n > 0 {
+					next, consumed = child, n
+					break search
+				}
+			}
+		}
+
+		if next == nil {
+			return line
+		}
+
+		current = next
+		keys = keys[consumed:]
+		line = current.Pos().Line
+	}
+
+	return line
+}
+
+func matchKeys(item *ast.ObjectItem, keys []string) int {
+	n := min(len(item.Keys), len(keys))
+	for i := 0; i < n; i++ {
+		key, _ := item.Keys[i].Token.Value().(string)
+		if !strings.EqualFold(key, keys[i]) {
+			return 0
+		}
+	}
+	return n
+}
@@ -0,0 +1,292 @@
+package config_test
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validate(t *testing.T, files map[string]string) []string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		require.NoError(t, os.WriteFile(path.Join(dir, name), []byte(contents), 0o644))
+	}
+
+	validationErrors, err := config.ValidateConfigDir(dir)
+	require.NoError(t, err)
+
+	var messages []string
+	for _, validationErr := range validationErrors {
+		messages = append(messages, strings.ReplaceAll(validationErr.Error(), dir+"/", ""))
+	}
+	return messages
+}
+
+func TestValidConfigPassesValidation(t *testing.T) {
+	messages := validate(t, map[string]string{
+		"jobs.hcl": `
+job "php-fpm" {
+  command = "/usr/sbin/php-fpm"
+  maxAttempts = 3
+  timestampFormat = "RFC3339"
+
+  watch "/etc/php/*.ini" {
+    signal = 12
+    restart = true
+  }
+
+  readiness {
+    http {
+      host = {
+        hostname = "localhost"
+        port = 8080
+      }
+      timeout = "2s"
+    }
+  }
+}
+
+job "nginx" {
+  command = "/usr/sbin/nginx"
+  dependsOn = ["php-fpm"]
+
+  lazy {
+    spinUpTimeout = "5s"
+    coolDownTimeout = "15m"
+  }
+
+  listen "0.0.0.0:8080" {
+    forward = "127.0.0.1:8081"
+  }
+}
+
+probe "redis" {
+  wait = true
+  redis {
+    host = {
+      hostname = "localhost"
+    }
+  }
+}
+
+probe "memcached" {
+  tcp {
+    host = {
+      hostname = "localhost"
+      port = 11211
+    }
+    timeout = "1s"
+  }
+}
+
+probe "postgres" {
+  postgres {
+    host = {
+      hostname = "localhost"
+    }
+    credentials = {
+      user = "ENV:PGUSER"
+      password = "ENV:PGPASSWORD"
+    }
+    database = "app"
+    sslMode = "disable"
+    query = "SELECT 1 FROM pg_tables LIMIT 1"
+    timeout = "2s"
+  }
+}
+
+probe "app" {
+  exec {
+    command = "php"
+    args = ["artisan", "health:check"]
+    env = ["APP_ENV=production"]
+    timeout = "10s"
+  }
+}
+
+probe "dns" {
+  udp {
+    host = {
+      hostname = "localhost"
+      port = 53
+    }
+    payload = "ping"
+    expectReply = true
+  }
+}
+`,
+	})
+
+	assert.Empty(t, messages)
+}
+
+func TestValidationErrorsContainFileAndLine(t *testing.T) {
+	messages := validate(t, map[string]string{
+		"a.hcl": `job "web" {
+  command = "/bin/web"
+  maxAttemps = 3
+  timestampFormat = "RFC9999"
+  dependsOn = ["missing"]
+
+  watch "/etc/foo" {
+    signal = 0
+  }
+
+  lazy {
+    spinUpTimeout = "5 seconds"
+  }
+}
+
+probe "db" {
+  http {
+    timeout = "3x"
+  }
+}
+`,
+		"b.hcl": `job "web" {
+  args = ["x"]
+}
+
+probe "db" {
+  wait = true
+}
+
+boot "setup" {
+  command = "/bin/setup"
+  timeout = "soon"
+}
+`,
+	})
+
+	assert.ElementsMatch(t, []string{
+		`a.hcl:3: unknown key "maxAttemps" in job "web"`,
+		`a.hcl:4: unknown timestampFormat "RFC9999" in job "web"`,
+		`a.hcl:5: job "web" depends on unknown job "missing"`,
+		`a.hcl:7: watch "/etc/foo" of job "web" has no signal configured`,
+		`a.hcl:12: invalid duration "5 seconds" for spinUpTimeout in job "web": time: unknown unit " seconds" in duration "5 seconds"`,
+		`a.hcl:18: invalid duration "3x" for timeout in probe "db": time: unknown unit "x" in duration "3x"`,
+		`b.hcl:1: duplicate job "web" (already defined at a.hcl:1)`,
+		`b.hcl:1: job "web" has no command`,
+		`b.hcl:5: duplicate probe "db" (already defined at a.hcl:16)`,
+		`b.hcl:5: probe "db" configures no probe backend`,
+		`b.hcl:11: invalid duration "soon" for timeout in boot job "setup": time: invalid duration "soon"`,
+	}, messages)
+}
+
+func TestDependencyCyclesAreReported(t *testing.T) {
+	messages := validate(t, map[string]string{
+		"a.hcl": `job "web" {
+  command = "/bin/web"
+  dependsOn = ["app"]
+}
+
+job "app" {
+  command = "/bin/app"
+  dependsOn = ["web"]
+}
+
+job "worker" {
+  command = "/bin/worker"
+  dependsOn = ["app", "worker"]
+}
+`,
+	})
+
+	assert.ElementsMatch(t, []string{
+		`a.hcl:3: dependency cycle detected: web -> app -> web`,
+		`a.hcl:13: dependency cycle detected: worker -> worker`,
+	}, messages)
+}
+
+func TestRestartConfigurationIsValidated(t *testing.T) {
+	messages := validate(t, map[string]string{
+		"a.hcl": `job "migrate" {
+  command = "/bin/migrate"
+  oneTime = true
+
+  restart {
+    policy = "always"
+  }
+}
+
+job "web" {
+  command = "/bin/web"
+
+  restart {
+    initialBackoff = "0s"
+    maxBackoff = "-1m"
+  }
+}
+`,
+	})
+
+	assert.ElementsMatch(t, []string{
+		`a.hcl:6: restart policy "always" cannot be used for one-time job "migrate"`,
+		`a.hcl:14: initialBackoff "0s" in restart configuration of job "web" must be greater than 0`,
+		`a.hcl:15: maxBackoff "-1m" in restart configuration of job "web" must be greater than 0`,
+	}, messages)
+}
+
+func TestProbeBackendsAreValidated(t *testing.T) {
+	messages := validate(t, map[string]string{
+		"a.hcl": `probe "memcached" {
+  tcp {
+    host = {
+      hostname = "localhost"
+    }
+  }
+}
+
+probe "statsd" {
+  udp {
+    host = {
+      hostname = "localhost"
+      port = 8125
+    }
+    timeout = "1 second"
+  }
+}
+
+probe "db" {
+  postgres {
+    sslMode = "on"
+  }
+}
+
+probe "app" {
+  exec {
+    args = ["health:check"]
+  }
+}
+`,
+	})
+
+	assert.ElementsMatch(t, []string{
+		`a.hcl:2: tcp probe of probe "memcached" has no port configured`,
+		`a.hcl:15: invalid duration "1 second" for timeout in probe "statsd": time: unknown unit " second" in duration "1 second"`,
+		`a.hcl:21: unknown sslMode "on" in probe "db"`,
+		`a.hcl:26: exec probe of probe "app" has no command`,
+	}, messages)
+}
+
+func TestValidateJob(t *testing.T) {
+	errs := config.ValidateJob(&config.JobConfig{
+		BaseJobConfig: config.BaseJobConfig{Name: "reindex", StopTimeout: "soon"},
+		DependsOn:     []string{"unknown"},
+	})
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	assert.ElementsMatch(t, []string{
+		`job "reindex" has no command`,
+		`invalid duration "soon" for stopTimeout in job "reindex": time: invalid duration "soon"`,
+	}, messages)
+}
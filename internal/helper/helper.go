@@ -1,11 +1,16 @@
 package helper
 
 import (
+	"fmt"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
 )
 
 func ResolveEnv(in string) string {
@@ -28,3 +33,76 @@ func AddValueToURLValuesIfNotEmpty(key, value string, q *url.Values) {
 		q.Add(key, value)
 	}
 }
+
+// ParseSignal parses a signal given by name (e.g. "SIGQUIT" or "QUIT") or by
+// number.
+func ParseSignal(in string) (syscall.Signal, error) {
+	if n, err := strconv.Atoi(in); err == nil {
+		if n <= 0 {
+			return 0, fmt.Errorf("invalid signal %q", in)
+		}
+		return syscall.Signal(n), nil
+	}
+
+	name := strings.ToUpper(in)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+
+	sig := unix.SignalNum(name)
+	if sig == 0 {
+		return 0, fmt.Errorf("unknown signal %q", in)
+	}
+	return sig, nil
+}
+
+var byteSizeUnits = map[string]int64{
+	"":   1,
+	"B":  1,
+	"KB": 1000,
+	"MB": 1000 * 1000,
+	"GB": 1000 * 1000 * 1000,
+	"K":  1 << 10,
+	"M":  1 << 20,
+	"G":  1 << 30,
+
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+}
+
+// ParseByteSize parses a size like "50MB", "512KiB" or "1024" into bytes.
+func ParseByteSize(in string) (int64, error) {
+	s := strings.TrimSpace(in)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	value, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", in)
+	}
+
+	unit, ok := byteSizeUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", in, s[i:])
+	}
+
+	return int64(value * float64(unit)), nil
+}
+
+// ParseDuration works like time.ParseDuration, but additionally supports
+// days ("7d") as unit.
+func ParseDuration(in string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(in, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", in)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(in)
+}
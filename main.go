@@ -2,8 +2,8 @@ package main
 
 import (
 	"github.com/mittwald/mittnite/cmd"
+	"github.com/mittwald/mittnite/pkg/proc"
 	log "github.com/sirupsen/logrus"
-	"os"
 )
 
 func init() {
@@ -11,11 +11,9 @@ func init() {
 	Formatter.TimestampFormat = "02-01-2006 15:04:05"
 	Formatter.FullTimestamp = true
 	log.SetFormatter(Formatter)
-	if os.Getenv("MITTNITE_LOG_LEVEL") == "debug" {
-		log.SetLevel(log.DebugLevel)
-	}
 }
 
 func main() {
+	proc.HandleLimitsShim()
 	cmd.Execute()
 }
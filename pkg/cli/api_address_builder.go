@@ -14,18 +14,20 @@ func (api *APIClient) buildHTTPClientAndURL() (*http.Client, *url.URL, error) {
 		return nil, nil, err
 	}
 	if u.Scheme != "unix" {
-		return &http.Client{}, u, nil
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = api.tlsConfig
+		return &http.Client{Transport: api.authTransport(transport)}, u, nil
 	}
 
 	socketPath := u.Path
 	u.Scheme = "http"
 	u.Host = "unix"
 	return &http.Client{
-		Transport: &http.Transport{
+		Transport: api.authTransport(&http.Transport{
 			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
 				return net.Dial("unix", socketPath)
 			},
-		},
+		}),
 	}, u, nil
 }
 
@@ -35,8 +37,15 @@ func (api *APIClient) buildWebsocketURL() (*websocket.Dialer, *url.URL, error) {
 		return nil, nil, err
 	}
 	if u.Scheme != "unix" {
-		u.Scheme = "ws"
-		return websocket.DefaultDialer, u, nil
+		dialer := *websocket.DefaultDialer
+		dialer.TLSClientConfig = api.tlsConfig
+
+		if u.Scheme == "https" || u.Scheme == "wss" {
+			u.Scheme = "wss"
+		} else {
+			u.Scheme = "ws"
+		}
+		return &dialer, u, nil
 	}
 	socketPath := u.Path
 
@@ -50,3 +59,34 @@ func (api *APIClient) buildWebsocketURL() (*websocket.Dialer, *url.URL, error) {
 	u.Host = "unix"
 	return dialer, u, nil
 }
+
+// authHeader returns the headers needed to authenticate at the API.
+func (api *APIClient) authHeader() http.Header {
+	header := http.Header{}
+	if api.apiToken != "" {
+		header.Set("Authorization", "Bearer "+api.apiToken)
+	}
+	return header
+}
+
+// authTransport wraps the given transport, so that the headers needed to
+// authenticate at the API are added to each request.
+func (api *APIClient) authTransport(transport http.RoundTripper) http.RoundTripper {
+	if api.apiToken == "" {
+		return transport
+	}
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		for key, values := range api.authHeader() {
+			req.Header[key] = values
+		}
+		return transport.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
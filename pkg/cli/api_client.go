@@ -1,11 +1,17 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"github.com/gorilla/websocket"
+	"github.com/mittwald/mittnite/pkg/probe"
 	"github.com/mittwald/mittnite/pkg/proc"
 	"net/http"
+	"os"
 )
 
 const (
@@ -18,14 +24,56 @@ const (
 
 type APIClient struct {
 	apiAddress string
+	apiToken   string
+	tlsConfig  *tls.Config
 }
 
-func NewApiClient(apiAddress string) *APIClient {
+// NewApiClient creates a client for the API at the given address. If apiToken
+// is not empty, it is sent as bearer token with each request. tlsConfig is
+// used for https addresses and may be nil.
+func NewApiClient(apiAddress, apiToken string, tlsConfig *tls.Config) *APIClient {
 	return &APIClient{
 		apiAddress: apiAddress,
+		apiToken:   apiToken,
+		tlsConfig:  tlsConfig,
 	}
 }
 
+// LoadTLSConfig builds the TLS configuration for connecting to the API. The
+// client certificate (certFile and keyFile) is needed if the API requires
+// mutual TLS; caFile is needed if the API's certificate is not signed by a
+// CA trusted by the system. It returns nil if all arguments are empty.
+func LoadTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
 func (api *APIClient) CallAction(job, action string) APIResponse {
 	switch action {
 	case ApiActionJobStart:
@@ -72,6 +120,50 @@ func (api *APIClient) JobStop(job string) APIResponse {
 	return NewAPIResponse(client.Post(url.String(), "application/json", nil))
 }
 
+// JobCreate creates a job from the given JSON job configuration.
+func (api *APIClient) JobCreate(jobConfig []byte) APIResponse {
+	client, url, err := api.buildHTTPClientAndURL()
+	if err != nil {
+		return &CommonAPIResponse{Error: err}
+	}
+
+	url.Path = "/v1/jobs"
+	return NewAPIResponse(client.Post(url.String(), "application/json", bytes.NewReader(jobConfig)))
+}
+
+func (api *APIClient) JobDelete(job string) APIResponse {
+	client, url, err := api.buildHTTPClientAndURL()
+	if err != nil {
+		return &CommonAPIResponse{Error: err}
+	}
+
+	url.Path = fmt.Sprintf("/v1/job/%s", job)
+	req, err := http.NewRequest(http.MethodDelete, url.String(), nil)
+	if err != nil {
+		return &CommonAPIResponse{Error: err}
+	}
+	return NewAPIResponse(client.Do(req))
+}
+
+// JobSignal sends a signal (like "SIGHUP", "HUP" or "1") to the job's process,
+// or to its whole process group if group is set.
+func (api *APIClient) JobSignal(job, signal string, group bool) APIResponse {
+	client, u, err := api.buildHTTPClientAndURL()
+	if err != nil {
+		return &CommonAPIResponse{Error: err}
+	}
+
+	qryValues := u.Query()
+	qryValues.Set("sig", signal)
+	if group {
+		qryValues.Set("group", "true")
+	}
+
+	u.RawQuery = qryValues.Encode()
+	u.Path = fmt.Sprintf("/v1/job/%s/signal", job)
+	return NewAPIResponse(client.Post(u.String(), "application/json", nil))
+}
+
 func (api *APIClient) JobStatus(job string) TypedAPIResponse[proc.CommonJobStatus] {
 	client, url, err := api.buildHTTPClientAndURL()
 	if err != nil {
@@ -92,6 +184,26 @@ func (api *APIClient) JobList() TypedAPIResponse[[]string] {
 	return *NewTypedAPIResponse(make([]string, 0))(client.Get(url.String()))
 }
 
+func (api *APIClient) ProbeList() TypedAPIResponse[map[string]*probe.ProbeResult] {
+	client, url, err := api.buildHTTPClientAndURL()
+	if err != nil {
+		return TypedAPIResponse[map[string]*probe.ProbeResult]{Error: err}
+	}
+
+	url.Path = "/v1/probes"
+	return *NewTypedAPIResponse(make(map[string]*probe.ProbeResult))(client.Get(url.String()))
+}
+
+func (api *APIClient) ProbeStatus(name string) TypedAPIResponse[probe.ProbeResult] {
+	client, url, err := api.buildHTTPClientAndURL()
+	if err != nil {
+		return TypedAPIResponse[probe.ProbeResult]{Error: err}
+	}
+
+	url.Path = fmt.Sprintf("/v1/probe/%s", name)
+	return *NewTypedAPIResponse(probe.ProbeResult{})(client.Get(url.String()))
+}
+
 func (api *APIClient) JobLogs(job string, follow bool, tailLen int) APIResponse {
 	dialer, url, err := api.buildWebsocketURL()
 	if err != nil {
@@ -122,5 +234,50 @@ func (api *APIClient) JobLogs(job string, follow bool, tailLen int) APIResponse
 			}
 		}
 	}
-	return NewStreamingAPIResponse(url, dialer, handler)
+	return NewStreamingAPIResponse(url, dialer, api.authHeader(), handler)
+}
+
+// Events streams the events of mittnite's jobs and probes. If format is nil,
+// the events are printed as JSON.
+func (api *APIClient) Events(follow bool, tailLen int, format func(proc.Event) string) APIResponse {
+	dialer, url, err := api.buildWebsocketURL()
+	if err != nil {
+		return &CommonAPIResponse{Error: fmt.Errorf("error building websocket url: %w", err)}
+	}
+
+	qryValues := url.Query()
+	qryValues.Add("taillen", fmt.Sprintf("%d", tailLen))
+	if follow {
+		qryValues.Add("follow", "true")
+	}
+
+	url.RawQuery = qryValues.Encode()
+	url.Path = "/v1/events"
+
+	handler := func(ctx context.Context, conn *websocket.Conn, msgChan chan []byte, errChan chan error) {
+		for {
+			select {
+			default:
+				_, msg, err := conn.ReadMessage()
+				if err != nil {
+					errChan <- err
+					return
+				}
+
+				if format != nil {
+					var event proc.Event
+					if err := json.Unmarshal(msg, &event); err != nil {
+						errChan <- fmt.Errorf("failed to decode event: %w", err)
+						return
+					}
+					msg = []byte(format(event))
+				}
+
+				msgChan <- msg
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+	return NewStreamingAPIResponse(url, dialer, api.authHeader(), handler)
 }
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/gorilla/websocket"
+	"io"
+	"net/http"
 	"net/url"
+	"strings"
 )
 
 var _ APIResponse = &StreamingAPIResponse{}
@@ -19,9 +22,10 @@ type StreamingAPIResponse struct {
 	errorChan     chan error
 	streamingFunc StreamingAPIResponseHandler
 	dialer        *websocket.Dialer
+	header        http.Header
 }
 
-func NewStreamingAPIResponse(url *url.URL, dialer *websocket.Dialer, streamingFunc StreamingAPIResponseHandler) APIResponse {
+func NewStreamingAPIResponse(url *url.URL, dialer *websocket.Dialer, header http.Header, streamingFunc StreamingAPIResponseHandler) APIResponse {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &StreamingAPIResponse{
 		url:           url,
@@ -31,6 +35,7 @@ func NewStreamingAPIResponse(url *url.URL, dialer *websocket.Dialer, streamingFu
 		errorChan:     make(chan error),
 		streamingFunc: streamingFunc,
 		dialer:        dialer,
+		header:        header,
 	}
 }
 
@@ -39,8 +44,12 @@ func (resp *StreamingAPIResponse) Err() error {
 }
 
 func (resp *StreamingAPIResponse) Print() error {
-	conn, _, err := resp.dialer.Dial(resp.url.String(), nil)
+	conn, httpResp, err := resp.dialer.Dial(resp.url.String(), resp.header)
 	if err != nil {
+		if httpResp != nil && httpResp.StatusCode >= 400 {
+			body, _ := io.ReadAll(httpResp.Body)
+			return fmt.Errorf("error dialing to %s: unexpected status code %d: %s", resp.url.String(), httpResp.StatusCode, strings.TrimSpace(string(body)))
+		}
 		return fmt.Errorf("error dialing to %s: %w", resp.url.String(), err)
 	}
 	defer func() {
@@ -0,0 +1,261 @@
+package logrotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Options configures when and how a log file is rotated.
+type Options struct {
+	MaxSize  int64         // size in bytes at which the file is rotated; 0 disables rotation
+	MaxFiles int           // number of rotated files to keep; 0 keeps all
+	MaxAge   time.Duration // age after which rotated files are deleted; 0 keeps them forever
+	Compress bool          // compress rotated files with gzip
+}
+
+// Writer is an io.WriteCloser that writes to a file and rotates it once it
+// reaches a certain size. Rotated files are named like the original file,
+// with a numeric suffix (".1" being the most recent one).
+type Writer struct {
+	path    string
+	options Options
+
+	lock        sync.Mutex
+	file        *os.File
+	size        int64
+	compressing sync.WaitGroup
+}
+
+// New opens (or creates) the file at the given path for appending.
+func New(path string, options Options) (*Writer, error) {
+	w := Writer{
+		path:    path,
+		options: options,
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o666)
+	if err != nil {
+		return err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	w.file = file
+	w.size = stat.Size()
+	return nil
+}
+
+// Write writes p to the file. If the file would exceed its maximum size, it
+// is rotated first; p is never split across files. If the file cannot be
+// rotated, p is written to the current file.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if w.options.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.options.MaxSize {
+		if err := w.rotate(); err != nil {
+			log.WithField("file", w.path).WithError(err).Warn("failed to rotate log file; continuing with the current file")
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the underlying file.
+func (w *Writer) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+	w.compressing.Wait()
+	return err
+}
+
+// Rotate rotates the file, regardless of its size. If rotating fails, the
+// current file is kept open, so that the Writer remains usable.
+func (w *Writer) Rotate() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		return os.ErrClosed
+	}
+
+	if err := w.rotate(); err != nil {
+		return fmt.Errorf("failed to rotate %s: %w", w.path, err)
+	}
+	return nil
+}
+
+// rotate renames the files while the current file is still open; it is only
+// replaced once the new file has been opened.
+func (w *Writer) rotate() error {
+	// the previous rotated file might still be being compressed
+	w.compressing.Wait()
+
+	rotated := w.rotatedFiles()
+
+	// shift all rotated files by one, starting with the oldest one
+	for i := len(rotated) - 1; i >= 0; i-- {
+		f := rotated[i]
+		if w.options.MaxFiles > 0 && f.index >= w.options.MaxFiles {
+			_ = os.Remove(f.path)
+			continue
+		}
+		if err := os.Rename(f.path, w.rotatedName(f.index+1, f.compressed)); err != nil {
+			return err
+		}
+	}
+
+	target := w.rotatedName(1, false)
+	if err := os.Rename(w.path, target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	previous := w.file
+	if err := w.open(); err != nil {
+		// keep writing to the (already renamed) current file
+		return err
+	}
+
+	if err := previous.Close(); err != nil {
+		log.WithField("file", target).WithError(err).Warn("failed to close rotated log file")
+	}
+
+	w.removeExpired()
+
+	if w.options.Compress {
+		w.compressing.Add(1)
+		go func() {
+			defer w.compressing.Done()
+			w.compress(target)
+		}()
+	}
+
+	return nil
+}
+
+type rotatedFile struct {
+	path       string
+	index      int
+	compressed bool
+	modTime    time.Time
+}
+
+func (w *Writer) rotatedName(index int, compressed bool) string {
+	name := w.path + "." + strconv.Itoa(index)
+	if compressed {
+		name += ".gz"
+	}
+	return name
+}
+
+// rotatedFiles returns all rotated files, sorted by their index
+func (w *Writer) rotatedFiles() []rotatedFile {
+	matches, _ := filepath.Glob(w.path + ".*")
+
+	var files []rotatedFile
+	for _, m := range matches {
+		suffix := strings.TrimPrefix(m, w.path+".")
+		compressed := strings.HasSuffix(suffix, ".gz")
+		index, err := strconv.Atoi(strings.TrimSuffix(suffix, ".gz"))
+		if err != nil || index < 1 {
+			continue
+		}
+
+		stat, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+
+		files = append(files, rotatedFile{path: m, index: index, compressed: compressed, modTime: stat.ModTime()})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].index < files[j].index
+	})
+
+	return files
+}
+
+func (w *Writer) removeExpired() {
+	if w.options.MaxAge <= 0 {
+		return
+	}
+
+	for _, f := range w.rotatedFiles() {
+		if time.Since(f.modTime) > w.options.MaxAge {
+			_ = os.Remove(f.path)
+		}
+	}
+}
+
+func (w *Writer) compress(path string) {
+	l := log.WithField("file", path)
+
+	if err := compressFile(path, path+".gz"); err != nil {
+		l.WithError(err).Error("failed to compress rotated log file")
+		return
+	}
+
+	if err := os.Remove(path); err != nil {
+		l.WithError(err).Error("failed to remove rotated log file after compressing it")
+	}
+}
+
+func compressFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,96 @@
+package logrotate_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/mittwald/mittnite/pkg/logrotate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileIsRotatedWhenMaxSizeIsExceeded(t *testing.T) {
+	file := path.Join(t.TempDir(), "test.log")
+
+	w, err := logrotate.New(file, logrotate.Options{MaxSize: 10, MaxFiles: 2})
+	require.NoError(t, err)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	assertFileContents(t, file, "fourth\n")
+	assertFileContents(t, file+".1", "third\n")
+	assertFileContents(t, file+".2", "second\n")
+
+	_, err = os.Stat(file + ".3")
+	assert.True(t, os.IsNotExist(err), "only maxFiles rotated files should be kept")
+}
+
+func TestWriterRemainsUsableWhenRotationFails(t *testing.T) {
+	file := path.Join(t.TempDir(), "test.log")
+
+	// the current file cannot be renamed to a non-empty directory
+	require.NoError(t, os.MkdirAll(path.Join(file+".1", "blocker"), 0o755))
+
+	w, err := logrotate.New(file, logrotate.Options{MaxSize: 10, MaxFiles: 1})
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err, "writes must not fail when the file cannot be rotated")
+	assert.Error(t, w.Rotate())
+
+	require.NoError(t, os.RemoveAll(file+".1"))
+
+	_, err = w.Write([]byte("third\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assertFileContents(t, file, "third\n")
+	assertFileContents(t, file+".1", "first\nsecond\n")
+}
+
+func TestRotatedFilesAreCompressed(t *testing.T) {
+	file := path.Join(t.TempDir(), "test.log")
+
+	w, err := logrotate.New(file, logrotate.Options{Compress: true})
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Rotate())
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	assertFileContents(t, file, "second\n")
+
+	_, err = os.Stat(file + ".1")
+	assert.True(t, os.IsNotExist(err), "uncompressed rotated file should be removed")
+
+	f, err := os.Open(file + ".1.gz")
+	require.NoError(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	contents, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(contents))
+}
+
+func assertFileContents(t *testing.T, file, expected string) {
+	t.Helper()
+
+	contents, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(contents))
+}
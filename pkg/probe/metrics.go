@@ -0,0 +1,99 @@
+package probe
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// kinds of probes, used as label values in metrics
+const (
+	KindProbe     = "probe"
+	KindReadiness = "readiness"
+	KindLiveness  = "liveness"
+)
+
+var (
+	probeSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mittnite_probe_success",
+		Help: "Whether the last execution of the probe succeeded (1) or not (0).",
+	}, []string{"probe", "kind"})
+
+	probeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mittnite_probe_duration_seconds",
+		Help:    "Duration of probe executions.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"probe", "kind"})
+)
+
+// StateChangeFunc is called when an instrumented probe succeeds for the first
+// time after failing, or vice versa. The first execution of a probe always
+// counts as a state change.
+type StateChangeFunc func(name, kind string, healthy bool, err error)
+
+var stateChangeFunc StateChangeFunc
+
+// OnStateChange registers a function that is called on state changes of all
+// instrumented probes. It must be called before any probe is executed.
+func OnStateChange(f StateChangeFunc) {
+	stateChangeFunc = f
+}
+
+type instrumentedProbe struct {
+	Probe
+
+	name     string
+	kind     string
+	success  prometheus.Gauge
+	duration prometheus.Observer
+
+	stateLock sync.Mutex
+	executed  bool
+	healthy   bool
+}
+
+// Instrument wraps the given probe, so that the result and the duration of
+// each execution are recorded as metrics, and state changes are reported (see
+// OnStateChange). For readiness and liveness checks, name is the name of the
+// job.
+func Instrument(p Probe, name, kind string) Probe {
+	return &instrumentedProbe{
+		Probe:    p,
+		name:     name,
+		kind:     kind,
+		success:  probeSuccess.WithLabelValues(name, kind),
+		duration: probeDuration.WithLabelValues(name, kind),
+	}
+}
+
+func (p *instrumentedProbe) Exec() error {
+	start := time.Now()
+	err := p.Probe.Exec()
+	p.duration.Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		p.success.Set(0)
+	} else {
+		p.success.Set(1)
+	}
+
+	p.recordState(err)
+
+	return err
+}
+
+func (p *instrumentedProbe) recordState(err error) {
+	healthy := err == nil
+
+	p.stateLock.Lock()
+	changed := !p.executed || p.healthy != healthy
+	p.executed = true
+	p.healthy = healthy
+	p.stateLock.Unlock()
+
+	if changed && stateChangeFunc != nil {
+		stateChangeFunc(p.name, p.kind, healthy, err)
+	}
+}
@@ -0,0 +1,83 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/mittwald/mittnite/internal/helper"
+	"github.com/mittwald/mittnite/pkg/reaper"
+	log "github.com/sirupsen/logrus"
+)
+
+// execProbeMaxOutput limits how much of a failing command's output is
+// reported in the probe result.
+const execProbeMaxOutput = 4096
+
+type execProbe struct {
+	command string
+	args    []string
+	env     []string
+	timeout time.Duration
+}
+
+func NewExecProbe(cfg *config.Exec) (*execProbe, error) {
+	cfg.Command = helper.ResolveEnv(cfg.Command)
+	cfg.Timeout = helper.ResolveEnv(cfg.Timeout)
+
+	if cfg.Command == "" {
+		return nil, errors.New("exec probe has no command")
+	}
+
+	timeout, err := parseProbeTimeout(cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &execProbe{
+		command: cfg.Command,
+		args:    cfg.Args,
+		env:     cfg.Env,
+		timeout: timeout,
+	}, nil
+}
+
+func (e *execProbe) Exec() error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, e.command, e.args...)
+	cmd.Env = append(os.Environ(), e.env...)
+	cmd.WaitDelay = time.Second
+
+	out, err := reaper.CombinedOutput(cmd)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("command timed out after %s", e.timeout)
+	}
+	if err != nil {
+		return execProbeError(err, out)
+	}
+
+	log.WithFields(log.Fields{"kind": "probe", "name": "exec", "status": "alive", "command": e.command}).Debug()
+
+	return nil
+}
+
+// execProbeError adds the (possibly truncated) output of the command to err.
+func execProbeError(err error, out []byte) error {
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return err
+	}
+
+	if len(out) > execProbeMaxOutput {
+		out = append([]byte("..."), out[len(out)-execProbeMaxOutput:]...)
+	}
+
+	return fmt.Errorf("%w: %s", err, out)
+}
@@ -0,0 +1,87 @@
+package probe
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/mittwald/mittnite/internal/helper"
+	log "github.com/sirupsen/logrus"
+)
+
+type postgresProbe struct {
+	dsn     string
+	host    string
+	query   string
+	timeout time.Duration
+}
+
+func NewPostgresProbe(cfg *config.Postgres) (*postgresProbe, error) {
+	cfg.User = helper.ResolveEnv(cfg.User)
+	cfg.Password = helper.ResolveEnv(cfg.Password)
+	cfg.Database = helper.ResolveEnv(cfg.Database)
+	cfg.Hostname = helper.ResolveEnv(cfg.Hostname)
+	cfg.Port = helper.SetDefaultStringIfEmpty(helper.ResolveEnv(cfg.Port), "5432", "port", "postgres")
+	cfg.SSLMode = helper.SetDefaultStringIfEmpty(helper.ResolveEnv(cfg.SSLMode), "prefer", "sslMode", "postgres")
+	cfg.Query = helper.ResolveEnv(cfg.Query)
+	cfg.Timeout = helper.ResolveEnv(cfg.Timeout)
+
+	if cfg.Query == "" {
+		cfg.Query = "SELECT 1"
+	}
+
+	timeout, err := parseProbeTimeout(cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// the context timeout is not applied while the connection is set up, so
+	// it needs to be passed to the driver as well (in whole seconds)
+	connectTimeout := int(math.Ceil(timeout.Seconds()))
+
+	host := net.JoinHostPort(cfg.Hostname, cfg.Port)
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   host,
+		Path:   "/" + cfg.Database,
+		RawQuery: url.Values{
+			"sslmode":         []string{cfg.SSLMode},
+			"connect_timeout": []string{strconv.Itoa(connectTimeout)},
+		}.Encode(),
+	}
+
+	return &postgresProbe{
+		dsn:     dsn.String(),
+		host:    host,
+		query:   cfg.Query,
+		timeout: timeout,
+	}, nil
+}
+
+func (p *postgresProbe) Exec() error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	db, err := sql.Open("postgres", p.dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	r, err := db.QueryContext(ctx, p.query)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	log.WithFields(log.Fields{"kind": "probe", "name": "postgres", "status": "alive", "host": p.host}).Debug()
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package probe
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/mittwald/mittnite/internal/helper"
+	log "github.com/sirupsen/logrus"
+)
+
+type tcpProbe struct {
+	addr    string
+	timeout time.Duration
+}
+
+func NewTCPProbe(cfg *config.TCP) (*tcpProbe, error) {
+	cfg.Hostname = helper.ResolveEnv(cfg.Hostname)
+	cfg.Port = helper.ResolveEnv(cfg.Port)
+	cfg.Timeout = helper.ResolveEnv(cfg.Timeout)
+
+	timeout, err := parseProbeTimeout(cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tcpProbe{
+		addr:    net.JoinHostPort(cfg.Hostname, cfg.Port),
+		timeout: timeout,
+	}, nil
+}
+
+func (t *tcpProbe) Exec() error {
+	conn, err := net.DialTimeout("tcp", t.addr, t.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	log.WithFields(log.Fields{"kind": "probe", "name": "tcp", "status": "alive", "host": t.addr}).Debug()
+
+	return nil
+}
+
+// parseProbeTimeout parses the timeout of a network probe, which defaults
+// to 5s.
+func parseProbeTimeout(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 5 * time.Second, nil
+	}
+
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout duration: %w", err)
+	}
+
+	return duration, nil
+}
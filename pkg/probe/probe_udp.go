@@ -0,0 +1,73 @@
+package probe
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/mittwald/mittnite/internal/helper"
+	log "github.com/sirupsen/logrus"
+)
+
+type udpProbe struct {
+	addr        string
+	payload     []byte
+	expectReply bool
+	reply       string
+	timeout     time.Duration
+}
+
+func NewUDPProbe(cfg *config.UDP) (*udpProbe, error) {
+	cfg.Hostname = helper.ResolveEnv(cfg.Hostname)
+	cfg.Port = helper.ResolveEnv(cfg.Port)
+	cfg.Payload = helper.ResolveEnv(cfg.Payload)
+	cfg.Reply = helper.ResolveEnv(cfg.Reply)
+	cfg.Timeout = helper.ResolveEnv(cfg.Timeout)
+
+	timeout, err := parseProbeTimeout(cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &udpProbe{
+		addr:        net.JoinHostPort(cfg.Hostname, cfg.Port),
+		payload:     []byte(cfg.Payload),
+		expectReply: cfg.ExpectReply || cfg.Reply != "",
+		reply:       cfg.Reply,
+		timeout:     timeout,
+	}, nil
+}
+
+func (u *udpProbe) Exec() error {
+	conn, err := net.DialTimeout("udp", u.addr, u.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(u.timeout)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(u.payload); err != nil {
+		return err
+	}
+
+	if u.expectReply {
+		buf := make([]byte, 64*1024)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return fmt.Errorf("no reply from udp service '%s': %w", u.addr, err)
+		}
+
+		if !strings.Contains(string(buf[:n]), u.reply) {
+			return fmt.Errorf("udp service '%s' replied with unexpected response %q", u.addr, buf[:n])
+		}
+	}
+
+	log.WithFields(log.Fields{"kind": "probe", "name": "udp", "status": "alive", "host": u.addr}).Debug()
+
+	return nil
+}
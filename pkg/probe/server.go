@@ -7,18 +7,29 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/mittwald/mittnite/internal/config"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 )
 
+var ErrNoProbeBackend = errors.New("no probe backend configured")
+
 type Handler struct {
 	cfg        *config.Ignition
 	probes     map[string]Probe
 	waitProbes map[string]Probe
+	jobs       JobReadinessSource
+}
+
+// SetJobReadinessSource registers a source for the readiness of jobs, which
+// will be included in the status response.
+func (h *Handler) SetJobReadinessSource(jobs JobReadinessSource) {
+	h.jobs = jobs
 }
 
 func (h *Handler) Wait(interrupt chan os.Signal) error {
@@ -39,7 +50,7 @@ func (h *Handler) Wait(interrupt chan os.Signal) error {
 					return nil
 				}
 				if err != nil {
-					log.WithFields(log.Fields{"kind": "probe", "name": i, "err": err}).Warn("not ready yet")
+					log.WithFields(log.Fields{"kind": "probe", "name": i, "err": err}).Info("not ready yet")
 					ready = false
 				}
 			}
@@ -57,37 +68,18 @@ func (h *Handler) Wait(interrupt chan os.Signal) error {
 
 func (h *Handler) HandleStatus(res http.ResponseWriter, req *http.Request) {
 	response := StatusResponse{
-		Probes: make(map[string]*ProbeResult),
-	}
-
-	results := make(chan *ProbeResult, len(h.probes))
-	timeout := time.NewTimer(1 * time.Second)
-
-	for i := range h.probes {
-		response.Probes[i] = &ProbeResult{i, false, "timed out"}
-
-		go func(p Probe, name string) {
-			err := p.Exec()
-
-			if err != nil {
-				results <- &ProbeResult{Name: name, OK: false, Message: err.Error()}
-			} else {
-				results <- &ProbeResult{Name: name, OK: true, Message: ""}
-			}
-		}(h.probes[i], i)
+		Probes: h.CheckAll(1 * time.Second),
 	}
 
 	success := true
+	for _, result := range response.Probes {
+		success = success && result.OK
+	}
 
-	for i := 0; i < len(h.probes); i++ {
-		select {
-		case result := <-results:
-			response.Probes[result.Name] = result
+	if h.jobs != nil {
+		response.Jobs = h.jobs.JobReadiness()
+		for _, result := range response.Jobs {
 			success = success && result.OK
-		case <-timeout.C:
-			success = false
-			log.WithFields(log.Fields{"kind": "probe"}).Error("timed out")
-			break
 		}
 	}
 
@@ -100,6 +92,68 @@ func (h *Handler) HandleStatus(res http.ResponseWriter, req *http.Request) {
 	_ = json.NewEncoder(res).Encode(&response)
 }
 
+// ProbeNames returns the names of all probes, sorted alphabetically.
+func (h *Handler) ProbeNames() []string {
+	names := make([]string, 0, len(h.probes))
+	for name := range h.probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check executes the probe with the given name; ok is false if there is no
+// such probe.
+func (h *Handler) Check(name string, timeout time.Duration) (result *ProbeResult, ok bool) {
+	p, ok := h.probes[name]
+	if !ok {
+		return nil, false
+	}
+	return execWithTimeout(p, name, timeout), true
+}
+
+// CheckAll executes all probes concurrently.
+func (h *Handler) CheckAll(timeout time.Duration) map[string]*ProbeResult {
+	results := make(map[string]*ProbeResult, len(h.probes))
+	resultChan := make(chan *ProbeResult, len(h.probes))
+
+	for name, p := range h.probes {
+		go func() {
+			resultChan <- execWithTimeout(p, name, timeout)
+		}()
+	}
+
+	for range h.probes {
+		result := <-resultChan
+		results[result.Name] = result
+	}
+
+	return results
+}
+
+func execWithTimeout(p Probe, name string, timeout time.Duration) *ProbeResult {
+	// buffered, so that the probe can finish after the timeout
+	errChan := make(chan error, 1)
+	start := time.Now()
+
+	go func() {
+		errChan <- p.Exec()
+	}()
+
+	select {
+	case err := <-errChan:
+		result := &ProbeResult{Name: name, OK: err == nil, Latency: time.Since(start)}
+		if err != nil {
+			result.Message = err.Error()
+		}
+		return result
+
+	case <-time.After(timeout):
+		log.WithFields(log.Fields{"kind": "probe", "name": name}).Error("timed out")
+		return &ProbeResult{Name: name, OK: false, Message: "timed out", Latency: timeout}
+	}
+}
+
 func NewProbeHandler(cfg *config.Ignition) (*Handler, error) {
 	probes, err := buildProbesFromConfig(cfg)
 	if err != nil {
@@ -107,13 +161,14 @@ func NewProbeHandler(cfg *config.Ignition) (*Handler, error) {
 	}
 	waitProbes := filterWaitProbes(cfg, probes)
 
-	handler := &Handler{cfg, probes, waitProbes}
+	handler := &Handler{cfg: cfg, probes: probes, waitProbes: waitProbes}
 	return handler, nil
 }
 
 func RunProbeServer(ph *Handler, signals chan os.Signal, probePort int) error {
 	m := mux.NewRouter()
 	m.Path("/status").HandlerFunc(ph.HandleStatus)
+	m.Path("/metrics").Handler(promhttp.Handler())
 
 	server := http.Server{
 		Addr:    fmt.Sprintf(":%d", probePort),
@@ -153,25 +208,15 @@ func buildProbesFromConfig(cfg *config.Ignition) (map[string]Probe, error) {
 	var errs []error
 
 	for i := range cfg.Probes {
-		if cfg.Probes[i].Filesystem != "" {
-			result[cfg.Probes[i].Name] = &filesystemProbe{cfg.Probes[i].Filesystem}
-		} else if cfg.Probes[i].MySQL != nil {
-			result[cfg.Probes[i].Name] = NewMySQLProbe(cfg.Probes[i].MySQL)
-		} else if cfg.Probes[i].Redis != nil {
-			result[cfg.Probes[i].Name] = NewRedisProbe(cfg.Probes[i].Redis)
-		} else if cfg.Probes[i].MongoDB != nil {
-			var err error
-			result[cfg.Probes[i].Name], err = NewMongoDBProbe(cfg.Probes[i].MongoDB)
-			if err != nil {
-				errs = append(errs, err)
-			}
-		} else if cfg.Probes[i].Amqp != nil {
-			result[cfg.Probes[i].Name] = NewAmqpProbe(cfg.Probes[i].Amqp)
-		} else if cfg.Probes[i].HTTP != nil {
-			result[cfg.Probes[i].Name] = NewHttpProbe(cfg.Probes[i].HTTP)
-		} else if cfg.Probes[i].SMTP != nil {
-			result[cfg.Probes[i].Name] = NewSmtpProbe(cfg.Probes[i].SMTP)
+		p, err := NewProbe(&cfg.Probes[i].ProbeBackends)
+		if errors.Is(err, ErrNoProbeBackend) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
+		result[cfg.Probes[i].Name] = Instrument(p, cfg.Probes[i].Name, KindProbe)
 	}
 
 	var err error
@@ -181,3 +226,33 @@ func buildProbesFromConfig(cfg *config.Ignition) (map[string]Probe, error) {
 
 	return result, err
 }
+
+// NewProbe builds a probe from the given backend configuration.
+func NewProbe(cfg *config.ProbeBackends) (Probe, error) {
+	switch {
+	case cfg.Filesystem != "":
+		return &filesystemProbe{cfg.Filesystem}, nil
+	case cfg.MySQL != nil:
+		return NewMySQLProbe(cfg.MySQL), nil
+	case cfg.Postgres != nil:
+		return NewPostgresProbe(cfg.Postgres)
+	case cfg.Redis != nil:
+		return NewRedisProbe(cfg.Redis), nil
+	case cfg.MongoDB != nil:
+		return NewMongoDBProbe(cfg.MongoDB)
+	case cfg.Amqp != nil:
+		return NewAmqpProbe(cfg.Amqp), nil
+	case cfg.HTTP != nil:
+		return NewHttpProbe(cfg.HTTP), nil
+	case cfg.SMTP != nil:
+		return NewSmtpProbe(cfg.SMTP), nil
+	case cfg.TCP != nil:
+		return NewTCPProbe(cfg.TCP)
+	case cfg.UDP != nil:
+		return NewUDPProbe(cfg.UDP)
+	case cfg.Exec != nil:
+		return NewExecProbe(cfg.Exec)
+	}
+
+	return nil, ErrNoProbeBackend
+}
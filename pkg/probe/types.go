@@ -1,15 +1,25 @@
 package probe
 
+import "time"
+
 type Probe interface {
 	Exec() error
 }
 
 type ProbeResult struct {
-	Name    string `json:"-"`
-	OK      bool   `json:"ok"`
-	Message string `json:"message,omitempty"`
+	Name    string        `json:"-"`
+	OK      bool          `json:"ok"`
+	Message string        `json:"message,omitempty"`
+	Latency time.Duration `json:"latency,omitempty"` // duration of the probe execution
+}
+
+// JobReadinessSource provides the readiness of all jobs that have a readiness
+// check configured, keyed by job name.
+type JobReadinessSource interface {
+	JobReadiness() map[string]*ProbeResult
 }
 
 type StatusResponse struct {
 	Probes map[string]*ProbeResult `json:"probes"`
+	Jobs   map[string]*ProbeResult `json:"jobs,omitempty"`
 }
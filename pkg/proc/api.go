@@ -2,6 +2,9 @@ package proc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -16,10 +19,13 @@ const (
 	contextKeyJob = "job"
 )
 
-func (api *Api) RegisterHandler(router *mux.Router, path string, methods []string, handler func(http.ResponseWriter, *http.Request)) {
+// RegisterHandler registers a handler for the given path and methods. When
+// tokens are required, calling the handler requires a token with the given
+// scope.
+func (api *Api) RegisterHandler(router *mux.Router, path string, methods []string, scope APIScope, handler func(http.ResponseWriter, *http.Request)) {
 	router.
 		Path(path).
-		HandlerFunc(handler).
+		Handler(scopedHandler{scope: scope, handler: handler}).
 		Methods(methods...)
 }
 
@@ -27,13 +33,57 @@ func (api *Api) RegisterMiddlewareFuncs(middlewareFunc ...mux.MiddlewareFunc) {
 	api.router.Use(middlewareFunc...)
 }
 
+// EnableTLS configures the api to use TLS when listening on a TCP port. If
+// clientCAFile is set, clients must present a certificate signed by one of the
+// CAs in that file (mutual TLS).
+func (api *Api) EnableTLS(certFile, keyFile, clientCAFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	api.tlsConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %q", clientCAFile)
+		}
+
+		api.tlsConfig.ClientCAs = pool
+		api.tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return nil
+}
+
+// AllowJobCreation enables creating jobs via api. As jobs can run arbitrary
+// commands, this should only be enabled if the api is not reachable by
+// untrusted clients.
+func (api *Api) AllowJobCreation() {
+	api.jobCreation = true
+}
+
 func (api *Api) Start() error {
 	api.srv = &http.Server{
-		Addr:    api.listenAddr,
-		Handler: api.router,
+		Addr:      api.listenAddr,
+		Handler:   api.router,
+		TLSConfig: api.tlsConfig,
 	}
 
-	log.Infof("remote api listens on %s", api.srv.Addr)
+	if api.tlsConfig != nil {
+		log.Infof("remote api listens on %s (TLS)", api.srv.Addr)
+	} else {
+		log.Infof("remote api listens on %s", api.srv.Addr)
+	}
 	if err := api.listen(); err != nil && err != http.ErrServerClosed {
 		return err
 	}
@@ -55,6 +105,9 @@ func (api *Api) listen() error {
 		return api.listenOnPort()
 	}
 
+	if api.tlsConfig != nil {
+		log.Warn("TLS is not used for the api, because it listens on a unix socket")
+	}
 	return api.listenOnUnixSocket(socketParts[1])
 }
 
@@ -71,5 +124,9 @@ func (api *Api) listenOnUnixSocket(socketFile string) error {
 }
 
 func (api *Api) listenOnPort() error {
+	if api.srv.TLSConfig != nil {
+		// the certificate is already part of the TLS config
+		return api.srv.ListenAndServeTLS("", "")
+	}
 	return api.srv.ListenAndServe()
 }
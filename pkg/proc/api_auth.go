@@ -0,0 +1,126 @@
+package proc
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type APIScope string
+
+const (
+	// APIScopeRead allows read-only requests (like job status and logs)
+	APIScopeRead APIScope = "read"
+	// APIScopeControl allows all requests, including starting and stopping jobs
+	APIScopeControl APIScope = "control"
+)
+
+type APIToken struct {
+	Token string
+	Scope APIScope
+}
+
+// ParseAPITokens parses a list of tokens separated by newlines or commas. Each
+// token is prefixed with its scope, like "control:s3cr3t". Empty lines and
+// lines starting with "#" are ignored.
+func ParseAPITokens(s string) ([]APIToken, error) {
+	var tokens []APIToken
+
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, entry := range strings.Split(line, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+
+			scope, token, ok := strings.Cut(entry, ":")
+			if !ok || token == "" {
+				return nil, fmt.Errorf("invalid token entry; expected <scope>:<token>")
+			}
+
+			switch APIScope(scope) {
+			case APIScopeRead, APIScopeControl:
+			default:
+				return nil, fmt.Errorf("invalid token scope %q; must be one of %q or %q", scope, APIScopeRead, APIScopeControl)
+			}
+
+			tokens = append(tokens, APIToken{Token: token, Scope: APIScope(scope)})
+		}
+	}
+
+	return tokens, nil
+}
+
+// scopedHandler is a handler that declares the scope required to call it.
+type scopedHandler struct {
+	scope   APIScope
+	handler http.HandlerFunc
+}
+
+func (h scopedHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.handler(w, req)
+}
+
+// requiredScope returns the scope declared by the handler of the route that
+// matched the request. Routes that do not declare a scope (or requests that
+// did not match any route) require the control scope.
+func requiredScope(req *http.Request) APIScope {
+	if route := mux.CurrentRoute(req); route != nil {
+		if h, ok := route.GetHandler().(scopedHandler); ok {
+			return h.scope
+		}
+	}
+	return APIScopeControl
+}
+
+// TokenAuthMiddleware returns a middleware that requires a bearer token for
+// all requests. Each request requires the scope that has been declared when
+// registering its handler (see Api.RegisterHandler).
+func TokenAuthMiddleware(tokens []APIToken) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			scope, ok := tokenScope(tokens, req)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="mittnite"`)
+				http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			required := requiredScope(req)
+			if required == APIScopeControl && scope != APIScopeControl {
+				http.Error(w, fmt.Sprintf("token does not have the %q scope", required), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+func tokenScope(tokens []APIToken, req *http.Request) (APIScope, bool) {
+	given, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || given == "" {
+		return "", false
+	}
+
+	// compare with all tokens in constant time, so that the time needed does
+	// not reveal anything about the configured tokens
+	var scope APIScope
+	found := false
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(given), []byte(t.Token)) == 1 && !found {
+			scope = t.Scope
+			found = true
+		}
+	}
+
+	return scope, found
+}
@@ -0,0 +1,105 @@
+package proc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenAuthMiddlewareChecksScopes(t *testing.T) {
+	tokens, err := ParseAPITokens("# comment\nread:reader\ncontrol:admin, read:other\n")
+	require.NoError(t, err)
+	require.Len(t, tokens, 3)
+
+	ok := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	api := NewApi("")
+	jobRouter := api.router.PathPrefix("/v1/job").Subrouter()
+	api.RegisterHandler(jobRouter, "/{job}/status", []string{http.MethodGet}, APIScopeRead, ok)
+	api.RegisterHandler(jobRouter, "/{job}/stop", []string{http.MethodPost}, APIScopeControl, ok)
+	api.RegisterHandler(api.router, "/v1/probes", []string{http.MethodGet}, APIScopeControl, ok)
+	api.RegisterMiddlewareFuncs(TokenAuthMiddleware(tokens))
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{http.MethodGet, "/v1/job/foo/status", "", http.StatusUnauthorized},
+		{http.MethodGet, "/v1/job/foo/status", "wrong", http.StatusUnauthorized},
+		{http.MethodGet, "/v1/job/foo/status", "reader", http.StatusOK},
+		{http.MethodPost, "/v1/job/foo/stop", "reader", http.StatusForbidden},
+		{http.MethodGet, "/v1/probes", "reader", http.StatusForbidden},
+		{http.MethodGet, "/v1/job/foo/status", "admin", http.StatusOK},
+		{http.MethodPost, "/v1/job/foo/stop", "admin", http.StatusOK},
+		{http.MethodGet, "/v1/probes", "admin", http.StatusOK},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		rec := httptest.NewRecorder()
+		api.router.ServeHTTP(rec, req)
+		assert.Equal(t, tc.status, rec.Code, "%s %s with token %q", tc.method, tc.path, tc.token)
+	}
+
+	_, err = ParseAPITokens("admin:secret")
+	assert.Error(t, err)
+}
+
+func TestJobStatusContainsNoSecrets(t *testing.T) {
+	tokens, err := ParseAPITokens("read:reader")
+	require.NoError(t, err)
+
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{{
+			BaseJobConfig: config.BaseJobConfig{
+				Name:         "app",
+				Command:      "true",
+				Controllable: true,
+			},
+			Readiness: &config.Readiness{
+				ProbeBackends: config.ProbeBackends{
+					Postgres: &config.Postgres{
+						Credentials: config.Credentials{User: "app", Password: "pg-s3cr3t"},
+						Host:        config.Host{Hostname: "localhost"},
+					},
+				},
+			},
+			Liveness: &config.Liveness{
+				ProbeBackends: config.ProbeBackends{
+					Redis: &config.Redis{
+						Host:     config.Host{Hostname: "localhost"},
+						Password: "redis-s3cr3t",
+					},
+				},
+			},
+		}},
+	}
+
+	runner := NewRunner(context.Background(), NewApi(""), false, ignitionConfig)
+	require.NoError(t, runner.Init())
+	runner.registerAPIV1Handlers()
+	runner.api.RegisterMiddlewareFuncs(TokenAuthMiddleware(tokens))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/job/app/status", nil)
+	req.Header.Set("Authorization", "Bearer reader")
+	rec := httptest.NewRecorder()
+	runner.api.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"User":"app"`)
+	assert.NotContains(t, rec.Body.String(), "pg-s3cr3t")
+	assert.NotContains(t, rec.Body.String(), "redis-s3cr3t")
+
+	assert.Equal(t, "pg-s3cr3t", ignitionConfig.Jobs[0].Readiness.Postgres.Password, "the job's config must not be modified")
+}
@@ -2,7 +2,6 @@ package proc
 
 import (
 	"bufio"
-	"bytes"
 	"container/list"
 	"context"
 	"errors"
@@ -23,24 +22,14 @@ var (
 
 	ProcessWillBeRestartedError = errors.New("process will be restarted")
 	ProcessWillBeStoppedError   = errors.New("process will be stopped")
+	ErrJobNotRunning            = errors.New("job is not running")
 )
 
 func (job *baseJob) SignalAll(sig syscall.Signal) {
-	errFunc := func(err error) {
-		if err != nil {
-			log.Warnf("failed to send signal %d to job %s: %s", sig, job.Config.Name, err.Error())
-		}
-	}
-
-	if job.cmd == nil || job.cmd.Process == nil {
-		errFunc(
-			fmt.Errorf("job is not running"),
-		)
-		return
-	}
-
 	log.WithField("job.name", job.Config.Name).Infof("sending signal %d to process group", sig)
-	errFunc(syscall.Kill(-job.cmd.Process.Pid, sig))
+	if err := job.SendSignal(sig, true); err != nil {
+		log.Warnf("failed to send signal %d to job %s: %s", sig, job.Config.Name, err.Error())
+	}
 }
 
 func (job *baseJob) Signal(sig os.Signal) {
@@ -50,25 +39,74 @@ func (job *baseJob) Signal(sig os.Signal) {
 		}
 	}
 
-	if job.cmd == nil || job.cmd.Process == nil {
-		errFunc(
-			fmt.Errorf("job is not running"),
-		)
+	cmd, _ := job.command()
+	if cmd == nil || cmd.Process == nil {
+		errFunc(ErrJobNotRunning)
 		return
 	}
 
 	log.WithField("job.name", job.Config.Name).Infof("sending signal %d to process", sig)
 	errFunc(
-		job.cmd.Process.Signal(sig),
+		cmd.Process.Signal(sig),
 	)
 }
 
+// SendSignal sends a signal to the job's process, or to its whole process
+// group if group is set. Unlike Signal and SignalAll, errors are returned
+// instead of being logged.
+func (job *baseJob) SendSignal(sig syscall.Signal, group bool) error {
+	cmd, _ := job.command()
+	if cmd == nil || cmd.Process == nil {
+		return ErrJobNotRunning
+	}
+
+	if group {
+		return syscall.Kill(-cmd.Process.Pid, sig)
+	}
+
+	if err := cmd.Process.Signal(sig); err != nil {
+		if errors.Is(err, os.ErrProcessDone) {
+			return ErrJobNotRunning
+		}
+		return err
+	}
+	return nil
+}
+
 func (job *baseJob) Reset() {
-	job.phase = JobPhase{}
+	job.phase.reset()
 }
 
 func (job *baseJob) MarkForRestart() {
-	job.restart = true
+	job.restart.Store(true)
+}
+
+// command returns the job's current (or last) command, and a channel that is
+// closed when it has exited.
+func (job *baseJob) command() (*exec.Cmd, chan struct{}) {
+	job.runLock.Lock()
+	defer job.runLock.Unlock()
+
+	return job.cmd, job.exited
+}
+
+// newRunContext replaces the job's run context; cancelling it via
+// interruptRun interrupts the job's current run, e.g. a crash loop back-off.
+// It must only be called from the goroutine running the job.
+func (job *baseJob) newRunContext() {
+	job.runLock.Lock()
+	defer job.runLock.Unlock()
+
+	job.ctx, job.interrupt = context.WithCancel(context.Background())
+}
+
+func (job *baseJob) interruptRun() {
+	job.runLock.Lock()
+	defer job.runLock.Unlock()
+
+	if job.interrupt != nil {
+		job.interrupt()
+	}
 }
 
 func (job *baseJob) IsControllable() bool {
@@ -79,22 +117,31 @@ func (job *baseJob) GetPhase() *JobPhase {
 	return &job.phase
 }
 
+// setEvents sets the event history that the job's events are published to.
+// It needs to be called before the job is run.
+func (job *baseJob) setEvents(events *ringBuffer[Event]) {
+	job.events = events
+	job.phase.setEvents(events)
+}
+
 func (job *baseJob) GetName() string {
 	return job.Config.Name
 }
 
 func (job *baseJob) StreamStdOut(ctx context.Context, outChan chan []byte, errChan chan error, follow bool, tailLen int) {
 	if len(job.Config.Stdout) == 0 {
+		job.streamLogBuffer(ctx, outChan, errChan, follow, tailLen)
 		return
 	}
-	job.readStdFile(ctx, &job.stdOutWg, job.Config.Stdout, outChan, errChan, follow, tailLen)
+	job.readStdFile(ctx, job.stdOutWg, job.Config.Stdout, outChan, errChan, follow, tailLen)
 }
 
 func (job *baseJob) StreamStdErr(ctx context.Context, outChan chan []byte, errChan chan error, follow bool, tailLen int) {
 	if len(job.Config.Stderr) == 0 {
+		job.streamLogBuffer(ctx, outChan, errChan, follow, tailLen)
 		return
 	}
-	job.readStdFile(ctx, &job.stdErrWg, job.Config.Stderr, outChan, errChan, follow, tailLen)
+	job.readStdFile(ctx, job.stdErrWg, job.Config.Stderr, outChan, errChan, follow, tailLen)
 }
 
 func (job *baseJob) StreamStdOutAndStdErr(ctx context.Context, outChan chan []byte, stdOutErrChan, stdErrErrChan chan error, follow bool, tailLen int) {
@@ -116,74 +163,77 @@ func (job *baseJob) startOnce(ctx context.Context, process chan<- *os.Process) e
 	cmd.Env = os.Environ()
 	cmd.Dir = job.Config.WorkingDirectory
 
-	// pipe command's stdout and stderr through timestamp function if timestamps are enabled
-	// otherwise just redirect stdout and err to job.stdout and job.stderr
-	if job.Config.EnableTimestamps {
-		stdoutPipe, err := cmd.StdoutPipe()
-		if err != nil {
-			return fmt.Errorf("failed to create stdout pipe for process: %s", err.Error())
-		}
-		defer stdoutPipe.Close()
-
-		stderrPipe, err := cmd.StderrPipe()
-		if err != nil {
-			return fmt.Errorf("failed to create stderr pipe for process: %s", err.Error())
-		}
-		defer stderrPipe.Close()
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
 
-		go job.logWithTimestamp(stdoutPipe, job.stdout)
-		go job.logWithTimestamp(stderrPipe, job.stderr)
-	} else {
-		cmd.Stdout = job.stdout
-		cmd.Stderr = job.stderr
+	if err := applyCredentials(cmd, job.Config); err != nil {
+		return fmt.Errorf("failed to start job %s: %w", job.Config.Name, err)
 	}
 
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
+	if err := applyLimits(cmd, job.Config); err != nil {
+		return fmt.Errorf("failed to start job %s: %w", job.Config.Name, err)
 	}
 
 	if job.Config.Env != nil {
 		cmd.Env = append(cmd.Env, job.Config.Env...)
 	}
 
+	stdout, stderr := job.outputWriters()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = outputDrainTimeout
+
 	l.Info("starting job")
 
 	if err := cmd.Start(); err != nil {
+		flushOutput(stdout, stderr)
 		return fmt.Errorf("failed to start job %s: %s", job.Config.Name, err.Error())
 	}
 
-	// Only set job.cmd if cmd.Start() was successful
+	// Only set job.cmd if the process was started successfully
+	exited := make(chan struct{})
+	job.runLock.Lock()
 	job.cmd = cmd
+	job.exited = exited
+	job.startedAt = time.Now()
+	job.runLock.Unlock()
 
 	if process != nil {
-		process <- job.cmd.Process
+		process <- cmd.Process
 	}
 
 	errChan := make(chan error, 1)
-	defer close(errChan)
 
 	go func() {
-		errChan <- job.cmd.Wait()
+		err := cmd.Wait()
+		flushOutput(stdout, stderr)
+		exitCode := cmd.ProcessState.ExitCode()
+		job.runLock.Lock()
+		job.lastExitCode = &exitCode
+		job.runLock.Unlock()
+
+		errChan <- err
+		close(exited)
 	}()
 
 	select {
 	// job errChan or failed
 	case err := <-errChan:
-		if err := syscall.Kill(-job.cmd.Process.Pid, syscall.SIGTERM); err != nil {
+		if err := syscall.Kill(-cmd.Process.Pid, job.stopSignal); err != nil {
 			if e, ok := err.(syscall.Errno); ok && e == 3 {
 				// this is fine; error 3 means that the process group does not exist anymore
 			} else {
-				l.WithError(err).Error("failed to send SIGTERM to job's process group")
+				l.WithError(err).Errorf("failed to send signal %d to job's process group", job.stopSignal)
 			}
 		}
 
-		if job.restart {
+		if job.restart.CompareAndSwap(true, false) {
 			l.Info("job stopped for restart")
-			job.restart = false
 			return ProcessWillBeRestartedError
 		}
 
-		if job.stop {
+		if job.stop.Load() {
 			l.Info("job stopped")
 			return ProcessWillBeStoppedError
 		}
@@ -194,14 +244,18 @@ func (job *baseJob) startOnce(ctx context.Context, process chan<- *os.Process) e
 		return err
 	case <-ctx.Done():
 		// ctx canceled, try to terminate job
-		_ = syscall.Kill(-job.cmd.Process.Pid, syscall.SIGTERM)
-		l.WithField("job.name", job.Config.Name).Info("sent SIGTERM to job's process group")
+		if job.beforeStop != nil {
+			job.beforeStop()
+		}
+		_ = syscall.Kill(-cmd.Process.Pid, job.stopSignal)
+		l.Infof("sent signal %d to job's process group", job.stopSignal)
 
 		select {
-		case <-time.After(time.Second * ShutdownWaitingTimeSeconds):
+		case <-time.After(job.stopTimeout):
 			// process seems to hang, kill process
-			_ = syscall.Kill(-job.cmd.Process.Pid, syscall.SIGKILL)
-			l.WithField("job.name", job.Config.Name).Error("forcefully killed job")
+			_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			l.WithField("job.stopTimeout", job.stopTimeout.String()).Error("forcefully killed job")
+			<-errChan
 			return nil
 
 		case err := <-errChan:
@@ -211,67 +265,68 @@ func (job *baseJob) startOnce(ctx context.Context, process chan<- *os.Process) e
 	}
 }
 
-func (job *baseJob) closeStdFiles() {
-	hasStdout := len(job.Config.Stdout) > 0
-	hasStderr := len(job.Config.Stderr) > 0 && job.Config.Stderr != job.Config.Stdout
-	if hasStdout {
-		job.stdout.Close()
+// terminate runs the job's beforeStop callback, sends the job's stop signal to
+// its process group, and kills the process group if the process has not
+// exited within the job's stop timeout. It neither waits for the callback nor
+// for the process to exit.
+func (job *baseJob) terminate() {
+	l := log.WithField("job.name", job.Config.Name)
+
+	cmd, exited := job.command()
+	if cmd == nil || cmd.Process == nil || exited == nil {
+		l.Warn("cannot stop job; job is not running")
+		return
 	}
 
-	if hasStderr {
-		job.stderr.Close()
+	select {
+	case <-exited:
+		// already exited, nothing to do
+		return
+	default:
 	}
-}
 
-func (job *baseJob) logWithTimestamp(r io.Reader, w io.Writer) {
-	l := log.WithField("job.name", job.Config.Name)
+	go func() {
+		if job.beforeStop != nil {
+			job.beforeStop()
 
-	var layout string
-
-	// has custom timestamp layout?
-	if job.Config.CustomTimestampFormat != "" {
-		layout = job.Config.CustomTimestampFormat
-		l.Infof("using custom timestamp layout '%s'", layout)
-	} else {
-		existingLayout, exists := TimeLayouts[job.Config.TimestampFormat]
-		if !exists {
-			layout = time.RFC3339
-			l.Warningf("unknown timestamp layout '%s', defaulting to RFC3339", job.Config.TimestampFormat)
-		} else {
-			layout = existingLayout
-			l.Infof("logging with timestamp layout '%s'", job.Config.TimestampFormat)
+			select {
+			case <-exited:
+				// exited while the callback was running
+				return
+			default:
+			}
 		}
-	}
-
-	scanner := bufio.NewScanner(r)
-	prefix := []byte{'['}
-	suffix := []byte{']', ' '}
-	newline := []byte{'\n'}
 
-	var timeBuffer []byte
-	var lineBuffer bytes.Buffer
+		l.Infof("sending signal %d to process group", job.stopSignal)
+		_ = syscall.Kill(-cmd.Process.Pid, job.stopSignal)
 
-	for scanner.Scan() {
-		// Reuse time buffer, completly avoiding allocations
-		timeBuffer = timeBuffer[:0]
-		timeBuffer = time.Now().AppendFormat(timeBuffer, layout)
-
-		// Reset line buffer
-		lineBuffer.Reset()
-		lineBuffer.Write(prefix)
-		lineBuffer.Write(timeBuffer)
-		lineBuffer.Write(suffix)
-		lineBuffer.Write(scanner.Bytes())
-		lineBuffer.Write(newline)
-
-		if _, err := w.Write(lineBuffer.Bytes()); err != nil {
-			l.Errorf("error writing log line for process: %v\n", err)
-			continue
+		select {
+		case <-exited:
+		case <-time.After(job.stopTimeout):
+			_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			l.WithField("job.stopTimeout", job.stopTimeout.String()).Error("forcefully killed job")
 		}
+	}()
+}
+
+// processStats returns the start time of the job's current process and the
+// exit code of its last one, if any.
+func (job *baseJob) processStats() (startedAt time.Time, lastExitCode *int) {
+	job.runLock.Lock()
+	defer job.runLock.Unlock()
+
+	return job.startedAt, job.lastExitCode
+}
+
+func (job *baseJob) closeStdFiles() {
+	hasStdout := len(job.Config.Stdout) > 0
+	hasStderr := len(job.Config.Stderr) > 0 && job.Config.Stderr != job.Config.Stdout
+	if c, ok := job.stdout.(io.Closer); ok && hasStdout {
+		c.Close()
 	}
 
-	if err := scanner.Err(); err != nil {
-		l.Errorf("error reading from process: %v\n", err)
+	if c, ok := job.stderr.(io.Closer); ok && hasStderr {
+		c.Close()
 	}
 }
 
@@ -282,7 +337,9 @@ func (job *baseJob) readStdFile(ctx context.Context, wg *sync.WaitGroup, filePat
 		return
 	}
 
-	defer stdFile.Close()
+	defer func() {
+		stdFile.Close()
+	}()
 
 	seekTail(ctx, wg, tailLen, stdFile, outChan)
 
@@ -321,6 +378,14 @@ func (job *baseJob) readStdFile(ctx context.Context, wg *sync.WaitGroup, filePat
 				return
 			}
 
+			// continue with the new file if the file has been rotated
+			if reopened := reopenIfRotated(stdFile, filePath); reopened != nil {
+				stdFile.Close()
+				stdFile = reopened
+				continue
+			}
+
+			time.Sleep(100 * time.Millisecond)
 			continue
 		case <-ctx.Done():
 			return
@@ -328,6 +393,77 @@ func (job *baseJob) readStdFile(ctx context.Context, wg *sync.WaitGroup, filePat
 	}
 }
 
+// streamLogBuffer sends the lines from the job's log buffer to outChan. The
+// log buffer contains the output of all streams without an output file.
+func (job *baseJob) streamLogBuffer(ctx context.Context, outChan chan []byte, errChan chan error, follow bool, tailLen int) {
+	if job.logBuffer == nil {
+		return
+	}
+
+	send := func(line []byte) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case outChan <- line:
+			return true
+		}
+	}
+
+	if !follow {
+		for _, line := range job.logBuffer.Tail(tailLen) {
+			if !send(line) {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case errChan <- io.EOF:
+		}
+		return
+	}
+
+	lines, sub, unsubscribe := job.logBuffer.Subscribe(tailLen)
+	defer unsubscribe()
+
+	for _, line := range lines {
+		if !send(line) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case line := <-sub:
+			if !send(line) {
+				return
+			}
+		}
+	}
+}
+
+// reopenIfRotated opens the file at the given path if it is not the same file
+// as the given (already opened) one anymore; otherwise it returns nil.
+func reopenIfRotated(f *os.File, filePath string) *os.File {
+	current, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+
+	latest, err := os.Stat(filePath)
+	if err != nil || os.SameFile(current, latest) {
+		return nil
+	}
+
+	reopened, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	return reopened
+}
+
 func seekTail(ctx context.Context, wg *sync.WaitGroup, lines int, stdFile *os.File, outChan chan []byte) {
 	wg.Add(1)
 	defer wg.Done()
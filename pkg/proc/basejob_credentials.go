@@ -0,0 +1,124 @@
+package proc
+
+import (
+	"fmt"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+
+	"github.com/mittwald/mittnite/internal/config"
+)
+
+// applyCredentials configures the command to run as the user and groups
+// configured for the job, and sets HOME and USER accordingly. Users and groups
+// can be given as names or as numeric ids; numeric ids do not need to exist in
+// the user database. Like initgroups(3), the user's supplementary groups are
+// used unless supplementary groups are configured explicitly. If only groups
+// are configured, the process keeps mittnite's user.
+func applyCredentials(cmd *exec.Cmd, c *config.BaseJobConfig) error {
+	if c.User == "" && c.Group == "" && len(c.SupplementaryGroups) == 0 {
+		return nil
+	}
+
+	credential := &syscall.Credential{
+		Uid: uint32(syscall.Getuid()),
+		Gid: uint32(syscall.Getgid()),
+	}
+
+	if c.User != "" {
+		u, err := lookupUser(c.User)
+		if err != nil {
+			return err
+		}
+
+		uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(u.Gid, 10, 32)
+		credential.Uid = uint32(uid)
+		credential.Gid = uint32(gid)
+
+		if len(c.SupplementaryGroups) == 0 {
+			groups, err := userGroupIDs(u)
+			if err != nil {
+				return err
+			}
+			credential.Groups = groups
+		}
+
+		cmd.Env = append(cmd.Env, "HOME="+u.HomeDir, "USER="+u.Username)
+	}
+
+	if c.Group != "" {
+		gid, err := lookupGroupID(c.Group)
+		if err != nil {
+			return err
+		}
+		credential.Gid = gid
+	}
+
+	for _, group := range c.SupplementaryGroups {
+		gid, err := lookupGroupID(group)
+		if err != nil {
+			return err
+		}
+		credential.Groups = append(credential.Groups, gid)
+	}
+
+	cmd.SysProcAttr.Credential = credential
+	return nil
+}
+
+func lookupUser(name string) (*user.User, error) {
+	u, err := user.Lookup(name)
+	if err == nil {
+		return u, nil
+	}
+
+	if _, parseErr := strconv.ParseUint(name, 10, 32); parseErr != nil {
+		return nil, fmt.Errorf("failed to look up user %q: %w", name, err)
+	}
+
+	u, err = user.LookupId(name)
+	if err == nil {
+		return u, nil
+	}
+
+	// like docker, allow numeric ids that are not present in the user database;
+	// unlike docker, do not fall back to the root group, but use the uid as gid
+	return &user.User{Uid: name, Gid: name, Username: name, HomeDir: "/"}, nil
+}
+
+// userGroupIDs returns the ids of all groups the user is a member of.
+func userGroupIDs(u *user.User) ([]uint32, error) {
+	ids, err := u.GroupIds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up groups of user %q: %w", u.Username, err)
+	}
+
+	groups := make([]uint32, 0, len(ids))
+	for _, id := range ids {
+		gid, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q of a group of user %q: %w", id, u.Username, err)
+		}
+		groups = append(groups, uint32(gid))
+	}
+	return groups, nil
+}
+
+func lookupGroupID(name string) (uint32, error) {
+	if gid, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return uint32(gid), nil
+	}
+
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up group %q: %w", name, err)
+	}
+
+	gid, err := strconv.ParseUint(g.Gid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q of group %q: %w", g.Gid, name, err)
+	}
+	return uint32(gid), nil
+}
@@ -0,0 +1,69 @@
+package proc
+
+import (
+	"os/exec"
+	"os/user"
+	"syscall"
+	"testing"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCredentialsWithNumericIDs(t *testing.T) {
+	cmd := exec.Command("true")
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+
+	err := applyCredentials(cmd, &config.BaseJobConfig{
+		User:                "54321",
+		Group:               "54322",
+		SupplementaryGroups: []string{"54323"},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, cmd.SysProcAttr.Credential)
+	assert.Equal(t, uint32(54321), cmd.SysProcAttr.Credential.Uid)
+	assert.Equal(t, uint32(54322), cmd.SysProcAttr.Credential.Gid)
+	assert.Equal(t, []uint32{54323}, cmd.SysProcAttr.Credential.Groups)
+	assert.Contains(t, cmd.Env, "HOME=/")
+	assert.Contains(t, cmd.Env, "USER=54321")
+}
+
+func TestApplyCredentialsUsesUIDAsGIDForUnknownNumericUser(t *testing.T) {
+	cmd := exec.Command("true")
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+
+	err := applyCredentials(cmd, &config.BaseJobConfig{User: "54321"})
+	require.NoError(t, err)
+
+	require.NotNil(t, cmd.SysProcAttr.Credential)
+	assert.Equal(t, uint32(54321), cmd.SysProcAttr.Credential.Uid)
+	assert.Equal(t, uint32(54321), cmd.SysProcAttr.Credential.Gid, "must not fall back to the root group")
+}
+
+func TestApplyCredentialsUsesSupplementaryGroupsOfUser(t *testing.T) {
+	u, err := user.Current()
+	require.NoError(t, err)
+	expected, err := userGroupIDs(u)
+	require.NoError(t, err)
+
+	cmd := exec.Command("true")
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+
+	err = applyCredentials(cmd, &config.BaseJobConfig{User: u.Username})
+	require.NoError(t, err)
+
+	require.NotNil(t, cmd.SysProcAttr.Credential)
+	assert.NotEmpty(t, cmd.SysProcAttr.Credential.Groups)
+	assert.Equal(t, expected, cmd.SysProcAttr.Credential.Groups)
+}
+
+func TestApplyCredentialsRejectsUnknownUser(t *testing.T) {
+	cmd := exec.Command("true")
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+
+	err := applyCredentials(cmd, &config.BaseJobConfig{User: "does-not-exist"})
+	assert.Error(t, err)
+	assert.Nil(t, cmd.SysProcAttr.Credential)
+}
@@ -0,0 +1,169 @@
+package proc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"golang.org/x/sys/unix"
+)
+
+// limitsShimName is used as argv[0] when mittnite re-executes itself to apply
+// resource limits to a job's process. Resource limits can only be applied
+// between fork and exec, which Go does not offer a hook for; so the process
+// is started as a copy of mittnite, which applies the limits (and drops
+// privileges afterwards, as raising limits may require them) and then
+// replaces itself with the actual command. Applying the limits after the
+// process has been started instead would not affect processes forked by it
+// in the meantime.
+const limitsShimName = "mittnite-limits-shim"
+
+// limitsShimEnabled is set by HandleLimitsShim; resource limits can only be
+// applied by executables that call it.
+var limitsShimEnabled bool
+
+type limitsShimSpec struct {
+	Limits     *config.Limits      `json:"limits"`
+	Credential *syscall.Credential `json:"credential,omitempty"`
+}
+
+// HandleLimitsShim needs to be called at the beginning of the main function
+// of executables that run jobs with resource limits. If the executable has
+// been started as limits shim, it applies the limits and executes the job's
+// command; in that case, it never returns.
+func HandleLimitsShim() {
+	if filepath.Base(os.Args[0]) == limitsShimName {
+		runLimitsShim()
+	}
+	limitsShimEnabled = true
+}
+
+// applyLimits wraps the command in the limits shim, if the job has resource
+// limits configured. It needs to be called after applyCredentials.
+func applyLimits(cmd *exec.Cmd, c *config.BaseJobConfig) error {
+	if c.Limits == nil || cmd.Err != nil {
+		return nil
+	}
+
+	if !limitsShimEnabled {
+		return errors.New("resource limits are not supported by this executable (proc.HandleLimitsShim has not been called)")
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to determine path of mittnite executable: %w", err)
+	}
+
+	spec, err := json.Marshal(limitsShimSpec{
+		Limits:     c.Limits,
+		Credential: cmd.SysProcAttr.Credential,
+	})
+	if err != nil {
+		return err
+	}
+
+	cmd.SysProcAttr.Credential = nil
+	cmd.Args = append([]string{limitsShimName, string(spec), cmd.Path}, cmd.Args...)
+	cmd.Path = self
+
+	return nil
+}
+
+// runLimitsShim is executed in place of mittnite's main function when mittnite
+// was started as limits shim; it never returns.
+func runLimitsShim() {
+	// the nice value is a per-thread attribute on Linux, so it needs to be set
+	// on the thread that calls exec
+	runtime.LockOSThread()
+
+	if err := execWithLimits(os.Args[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %s\n", limitsShimName, err)
+	}
+	os.Exit(127)
+}
+
+func execWithLimits(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("expected at least 3 arguments, got %d", len(args))
+	}
+
+	var spec limitsShimSpec
+	if err := json.Unmarshal([]byte(args[0]), &spec); err != nil {
+		return fmt.Errorf("invalid limits spec: %w", err)
+	}
+
+	if err := setLimits(spec.Limits); err != nil {
+		return err
+	}
+
+	if c := spec.Credential; c != nil {
+		groups := make([]int, len(c.Groups))
+		for i, gid := range c.Groups {
+			groups[i] = int(gid)
+		}
+
+		if err := syscall.Setgroups(groups); err != nil {
+			return fmt.Errorf("failed to set supplementary groups: %w", err)
+		}
+		if err := syscall.Setgid(int(c.Gid)); err != nil {
+			return fmt.Errorf("failed to set gid %d: %w", c.Gid, err)
+		}
+		if err := syscall.Setuid(int(c.Uid)); err != nil {
+			return fmt.Errorf("failed to set uid %d: %w", c.Uid, err)
+		}
+	}
+
+	return syscall.Exec(args[1], args[2:], os.Environ())
+}
+
+func setLimits(l *config.Limits) error {
+	if l == nil {
+		return nil
+	}
+
+	rlimits := []struct {
+		name     string
+		resource int
+		value    *int
+	}{
+		{"nofile", syscall.RLIMIT_NOFILE, l.NoFile},
+		{"nproc", unix.RLIMIT_NPROC, l.NProc},
+		{"core", syscall.RLIMIT_CORE, l.Core},
+	}
+
+	for _, r := range rlimits {
+		if r.value == nil {
+			continue
+		}
+
+		limit := uint64(unix.RLIM_INFINITY)
+		if *r.value >= 0 {
+			limit = uint64(*r.value)
+		}
+
+		if err := syscall.Setrlimit(r.resource, &syscall.Rlimit{Cur: limit, Max: limit}); err != nil {
+			return fmt.Errorf("failed to set %s limit to %d: %w", r.name, *r.value, err)
+		}
+	}
+
+	if l.Nice != nil {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, 0, *l.Nice); err != nil {
+			return fmt.Errorf("failed to set nice value to %d: %w", *l.Nice, err)
+		}
+	}
+
+	if l.OOMScoreAdj != nil {
+		if err := os.WriteFile("/proc/self/oom_score_adj", []byte(strconv.Itoa(*l.OOMScoreAdj)), 0); err != nil {
+			return fmt.Errorf("failed to set oom_score_adj to %d: %w", *l.OOMScoreAdj, err)
+		}
+	}
+
+	return nil
+}
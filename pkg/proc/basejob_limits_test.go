@@ -0,0 +1,36 @@
+package proc
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitsAreAppliedToProcess(t *testing.T) {
+	output := path.Join(t.TempDir(), "output")
+	nofile, oomScoreAdj := 512, 500
+
+	job, err := newBaseJob(&config.BaseJobConfig{
+		Name:    "limited",
+		Command: "sh",
+		Args:    []string{"-c", "ulimit -n; cat /proc/self/oom_score_adj"},
+		Stdout:  output,
+		Limits: &config.Limits{
+			NoFile:      &nofile,
+			OOMScoreAdj: &oomScoreAdj,
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, job.startOnce(context.Background(), nil))
+
+	contents, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"512", "500"}, strings.Fields(string(contents)))
+}
@@ -0,0 +1,211 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// lines longer than this are split
+	maxOutputLineLength = 64 * 1024
+
+	// how long to wait for the output of a process to be processed after
+	// it exited (e.g. when a forked child keeps the output pipes open)
+	outputDrainTimeout = 1 * time.Second
+)
+
+// outputWriter is connected to the output of a job's process if the output
+// needs to be processed by mittnite; everything written to it is processed
+// line by line by logWithTimestamp.
+type outputWriter struct {
+	*io.PipeWriter
+	done chan struct{} // closed when all output has been processed
+}
+
+// lineFormat describes how logWithTimestamp processes the lines of an output
+// stream.
+type lineFormat struct {
+	stream string     // "stdout" or "stderr"
+	layout string     // timestamp layout; empty if timestamps are disabled
+	format string     // see config.LogFormat*
+	buffer *logBuffer // optional; receives a copy of every line
+}
+
+// jsonOutputLine is a line of a job's output in the "json" log format.
+type jsonOutputLine struct {
+	Job     string `json:"job"`
+	Stream  string `json:"stream"`
+	Time    string `json:"ts"`
+	Message string `json:"msg"`
+}
+
+// outputWriters returns the writers that the process' stdout and stderr
+// should be connected to. The output is only processed by mittnite if
+// necessary; otherwise the process writes to the job's output files directly.
+// Writers returned by this need to be passed to flushOutput eventually.
+func (job *baseJob) outputWriters() (stdout, stderr io.Writer) {
+	var layout string
+	if job.Config.EnableTimestamps {
+		layout = job.timestampLayout()
+	}
+
+	return job.outputWriter(job.stdout, "stdout", job.Config.Stdout, layout),
+		job.outputWriter(job.stderr, "stderr", job.Config.Stderr, layout)
+}
+
+func (job *baseJob) outputWriter(w io.Writer, stream, file, layout string) io.Writer {
+	f := lineFormat{stream: stream, layout: layout, format: config.LogFormatRaw}
+
+	// output that is not written to a file is written to mittnite's own
+	// output in the configured format, and kept in the log buffer
+	if file == "" {
+		f.buffer = job.logBuffer
+		if job.Config.LogFormat != "" {
+			f.format = job.Config.LogFormat
+		}
+	}
+
+	if _, isFile := w.(*os.File); isFile && f.layout == "" && f.format == config.LogFormatRaw && f.buffer == nil {
+		return w
+	}
+
+	r, pw := io.Pipe()
+	o := outputWriter{PipeWriter: pw, done: make(chan struct{})}
+
+	go func() {
+		defer close(o.done)
+		job.logWithTimestamp(r, w, f)
+	}()
+
+	return &o
+}
+
+func (job *baseJob) timestampLayout() string {
+	l := log.WithField("job.name", job.Config.Name)
+
+	// has custom timestamp layout?
+	if job.Config.CustomTimestampFormat != "" {
+		l.Infof("using custom timestamp layout '%s'", job.Config.CustomTimestampFormat)
+		return job.Config.CustomTimestampFormat
+	}
+
+	layout, exists := TimeLayouts[job.Config.TimestampFormat]
+	if !exists {
+		l.Warningf("unknown timestamp layout '%s', defaulting to RFC3339", job.Config.TimestampFormat)
+		return time.RFC3339
+	}
+
+	l.Infof("logging with timestamp layout '%s'", job.Config.TimestampFormat)
+	return layout
+}
+
+// logWithTimestamp reads the output of a process line by line, prefixes
+// each line with a timestamp (if enabled), and writes it to w in the
+// configured log format.
+func (job *baseJob) logWithTimestamp(r io.Reader, w io.Writer, f lineFormat) {
+	l := log.WithField("job.name", job.Config.Name)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxOutputLineLength)
+	scanner.Split(scanLines)
+
+	prefix := []byte{'['}
+	suffix := []byte{']', ' '}
+
+	var timeBuffer []byte
+	var lineBuffer, formatted bytes.Buffer
+
+	for scanner.Scan() {
+		now := time.Now()
+
+		// Reset line buffer
+		lineBuffer.Reset()
+		if f.layout != "" {
+			// Reuse time buffer, completly avoiding allocations
+			timeBuffer = now.AppendFormat(timeBuffer[:0], f.layout)
+
+			lineBuffer.Write(prefix)
+			lineBuffer.Write(timeBuffer)
+			lineBuffer.Write(suffix)
+		}
+		lineBuffer.Write(scanner.Bytes())
+
+		if f.buffer != nil {
+			f.buffer.Add(bytes.Clone(lineBuffer.Bytes()))
+		}
+
+		formatted.Reset()
+		job.formatLine(&formatted, f, now, lineBuffer.Bytes(), scanner.Bytes())
+
+		if _, err := w.Write(formatted.Bytes()); err != nil {
+			l.Errorf("error writing log line for process: %v", err)
+			continue
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		l.WithError(err).Error("error reading output of process")
+
+		// keep the process from blocking on writes to its output
+		_, _ = io.Copy(io.Discard, r)
+	}
+}
+
+// formatLine writes the line to out according to the log format and appends a
+// newline. In the "json" format, the line is written without the timestamp
+// prefix, and the time is written to the "ts" field in RFC3339 format instead,
+// so that it can be parsed regardless of the job's timestamp layout.
+func (job *baseJob) formatLine(out *bytes.Buffer, f lineFormat, now time.Time, line, rawLine []byte) {
+	switch f.format {
+	case config.LogFormatPrefixed:
+		out.WriteByte('[')
+		out.WriteString(job.Config.Name)
+		out.WriteString("] ")
+		out.Write(line)
+		out.WriteByte('\n')
+
+	case config.LogFormatJSON:
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+
+		// encoding these fields can not fail; invalid UTF-8 is replaced
+		_ = enc.Encode(jsonOutputLine{
+			Job:     job.Config.Name,
+			Stream:  f.stream,
+			Time:    now.Format(time.RFC3339Nano),
+			Message: string(rawLine),
+		})
+
+	default:
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+}
+
+// scanLines splits the output into lines like bufio.ScanLines, but splits
+// lines that are longer than maxOutputLineLength instead of failing.
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	if advance == 0 && token == nil && err == nil && len(data) >= maxOutputLineLength {
+		return maxOutputLineLength, data[:maxOutputLineLength], nil
+	}
+	return advance, token, err
+}
+
+// flushOutput closes the given writers (see outputWriters) and waits until
+// their output, including an incomplete last line, has been processed.
+func flushOutput(writers ...io.Writer) {
+	for _, w := range writers {
+		if o, ok := w.(*outputWriter); ok {
+			_ = o.Close()
+			<-o.done
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package proc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputFilesAreRotated(t *testing.T) {
+	output := path.Join(t.TempDir(), "output.log")
+
+	job, err := newBaseJob(&config.BaseJobConfig{
+		Name:        "chatty",
+		Command:     "sh",
+		Args:        []string{"-c", "for i in 1 2 3 4 5; do echo line-$i; done"},
+		Stdout:      output,
+		LogRotation: &config.LogRotation{MaxSize: "14B", MaxFiles: 1},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, job.startOnce(context.Background(), nil))
+
+	current, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "line-5\n", string(current))
+
+	rotated, err := os.ReadFile(output + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "line-3\nline-4\n", string(rotated))
+
+	_, err = os.Stat(output + ".2")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestInitDoesNotKeepOutputFilesOpen(t *testing.T) {
+	openFiles := func() int {
+		entries, err := os.ReadDir("/proc/self/fd")
+		require.NoError(t, err)
+		return len(entries)
+	}
+
+	output := path.Join(t.TempDir(), "output.log")
+	before := openFiles()
+
+	_, err := newBaseJob(&config.BaseJobConfig{
+		Name:        "chatty",
+		Command:     "true",
+		Stdout:      output,
+		Stderr:      output + ".err",
+		LogRotation: &config.LogRotation{MaxSize: "1MB"},
+	})
+	require.NoError(t, err)
+
+	assert.FileExists(t, output)
+	assert.Equal(t, before, openFiles())
+}
+
+func TestOutputWithoutFileIsCapturedInLogBuffer(t *testing.T) {
+	job, err := newBaseJob(&config.BaseJobConfig{
+		Name:    "chatty",
+		Command: "sh",
+		Args:    []string{"-c", "echo out; echo err >&2"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, job.startOnce(context.Background(), nil))
+
+	lines := job.logBuffer.Tail(-1)
+	assert.ElementsMatch(t, [][]byte{[]byte("out"), []byte("err")}, lines)
+}
+
+func TestLogBufferKeepsLastLines(t *testing.T) {
+	buffer := newLogBuffer(3)
+	for _, line := range []string{"1", "2", "3", "4"} {
+		buffer.Add([]byte(line))
+	}
+
+	assert.Equal(t, [][]byte{[]byte("2"), []byte("3"), []byte("4")}, buffer.Tail(-1))
+	assert.Equal(t, [][]byte{[]byte("4")}, buffer.Tail(1))
+
+	lines, sub, unsubscribe := buffer.Subscribe(0)
+	defer unsubscribe()
+	assert.Empty(t, lines)
+
+	buffer.Add([]byte("5"))
+	assert.Equal(t, []byte("5"), <-sub)
+}
+
+func TestOutputIsWrittenInLogFormat(t *testing.T) {
+	for format, expected := range map[string]string{
+		config.LogFormatRaw:      "hello <world>\n",
+		config.LogFormatPrefixed: "[greeter] hello <world>\n",
+		config.LogFormatJSON:     `{"job":"greeter","stream":"stderr","ts":"TS","msg":"hello <world>"}` + "\n",
+	} {
+		t.Run(format, func(t *testing.T) {
+			job, err := newBaseJob(&config.BaseJobConfig{Name: "greeter", LogFormat: format})
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			job.stderr = &out
+
+			_, stderr := job.outputWriters()
+			_, err = stderr.Write([]byte("hello <world>\n"))
+			require.NoError(t, err)
+			flushOutput(stderr)
+
+			actual := regexp.MustCompile(`"ts":"[^"]+"`).ReplaceAllString(out.String(), `"ts":"TS"`)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestJSONOutputUsesRFC3339Timestamps(t *testing.T) {
+	job, err := newBaseJob(&config.BaseJobConfig{
+		Name:             "greeter",
+		LogFormat:        config.LogFormatJSON,
+		EnableTimestamps: true,
+		TimestampFormat:  "Kitchen",
+	})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	job.stdout = &out
+
+	stdout, _ := job.outputWriters()
+	_, err = stdout.Write([]byte("hello"))
+	require.NoError(t, err)
+	flushOutput(stdout)
+
+	var line jsonOutputLine
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "hello", line.Message)
+
+	_, err = time.Parse(time.RFC3339Nano, line.Time)
+	assert.NoError(t, err, "ts must be in RFC3339 format")
+}
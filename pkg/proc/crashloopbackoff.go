@@ -1,17 +1,126 @@
 package proc
 
 import (
+	"fmt"
+	"math/rand"
 	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
 )
 
-// calculates the next backOff based on the current backOff
-func calculateNextBackOff(currBackOff, maxBackoff time.Duration) time.Duration {
-	if currBackOff.Seconds() <= 1 {
-		return 2 * time.Second
+// restartPolicy describes whether and how a job is restarted after its process
+// exited; see config.Restart.
+type restartPolicy struct {
+	policy         string
+	initialBackOff time.Duration
+	maxBackOff     time.Duration
+	multiplier     float64
+	jitter         float64
+	resetAfter     time.Duration // 0 means "after the current backOff"
+}
+
+func newRestartPolicy(c *config.JobConfig) (*restartPolicy, error) {
+	p := restartPolicy{
+		policy:         config.RestartPolicyAlways,
+		initialBackOff: 1 * time.Second,
+		maxBackOff:     300 * time.Second,
+		multiplier:     2,
 	}
-	next := time.Duration(2*currBackOff.Seconds()) * time.Second
-	if next.Seconds() > maxBackoff.Seconds() {
-		return maxBackoff
+
+	if c.OneTime {
+		p.policy = config.RestartPolicyOnFailure
+	}
+
+	r := c.Restart
+	if r == nil {
+		return &p, nil
+	}
+
+	switch r.Policy {
+	case "":
+	case config.RestartPolicyAlways:
+		if c.OneTime {
+			return nil, fmt.Errorf("restart policy %q cannot be used for one-time jobs", r.Policy)
+		}
+		p.policy = r.Policy
+	case config.RestartPolicyOnFailure, config.RestartPolicyNever:
+		p.policy = r.Policy
+	default:
+		return nil, fmt.Errorf("invalid restart policy %q", r.Policy)
+	}
+
+	var err error
+	durations := []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"initialBackoff", r.InitialBackoff, &p.initialBackOff},
+		{"maxBackoff", r.MaxBackoff, &p.maxBackOff},
+		{"resetAfter", r.ResetAfter, &p.resetAfter},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		if *d.target, err = time.ParseDuration(d.value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", d.name, err)
+		}
+	}
+
+	if p.initialBackOff <= 0 || p.maxBackOff <= 0 {
+		return nil, fmt.Errorf("initialBackoff and maxBackoff must be greater than 0")
+	}
+
+	if r.Multiplier != 0 {
+		if r.Multiplier < 1 {
+			return nil, fmt.Errorf("invalid multiplier %v: must be at least 1", r.Multiplier)
+		}
+		p.multiplier = r.Multiplier
+	}
+
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return nil, fmt.Errorf("invalid jitter %v: must be between 0 and 1", r.Jitter)
+	}
+	p.jitter = r.Jitter
+
+	return &p, nil
+}
+
+// restartOnSuccess returns true if the job should be restarted after its
+// process exited successfully.
+func (p *restartPolicy) restartOnSuccess() bool {
+	return p.policy == config.RestartPolicyAlways
+}
+
+// restartOnFailure returns true if the job should be restarted after its
+// process failed.
+func (p *restartPolicy) restartOnFailure() bool {
+	return p.policy != config.RestartPolicyNever
+}
+
+// shouldReset returns true if a process that ran for the given duration was
+// running long enough for the backOff and attempt counter to be reset.
+func (p *restartPolicy) shouldReset(ranFor, currBackOff time.Duration) bool {
+	if p.resetAfter > 0 {
+		return ranFor > p.resetAfter
+	}
+	return ranFor > currBackOff
+}
+
+// calculates the next backOff based on the current backOff
+func (p *restartPolicy) nextBackOff(currBackOff time.Duration) time.Duration {
+	next := time.Duration(float64(currBackOff) * p.multiplier)
+	if next > p.maxBackOff {
+		return p.maxBackOff
 	}
 	return next
 }
+
+// withJitter randomly varies the given backOff by the configured jitter
+func (p *restartPolicy) withJitter(backOff time.Duration) time.Duration {
+	if p.jitter == 0 {
+		return backOff
+	}
+	return time.Duration(float64(backOff) * (1 + p.jitter*(2*rand.Float64()-1)))
+}
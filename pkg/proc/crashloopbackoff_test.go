@@ -0,0 +1,69 @@
+package proc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRestartPolicy(t *testing.T) {
+	p, err := newRestartPolicy(&config.JobConfig{})
+	require.NoError(t, err)
+
+	assert.True(t, p.restartOnSuccess())
+	assert.True(t, p.restartOnFailure())
+	assert.Equal(t, 2*time.Second, p.nextBackOff(1*time.Second))
+	assert.Equal(t, 300*time.Second, p.nextBackOff(200*time.Second))
+}
+
+func TestOneTimeJobsAreOnlyRestartedOnFailure(t *testing.T) {
+	p, err := newRestartPolicy(&config.JobConfig{OneTime: true})
+	require.NoError(t, err)
+
+	assert.False(t, p.restartOnSuccess())
+	assert.True(t, p.restartOnFailure())
+}
+
+func TestConfiguredRestartPolicy(t *testing.T) {
+	p, err := newRestartPolicy(&config.JobConfig{
+		Restart: &config.Restart{
+			Policy:         config.RestartPolicyNever,
+			InitialBackoff: "100ms",
+			MaxBackoff:     "1s",
+			Multiplier:     3,
+			Jitter:         0.5,
+			ResetAfter:     "10s",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.False(t, p.restartOnSuccess())
+	assert.False(t, p.restartOnFailure())
+	assert.Equal(t, 300*time.Millisecond, p.nextBackOff(p.initialBackOff))
+	assert.Equal(t, 1*time.Second, p.nextBackOff(900*time.Millisecond))
+	assert.False(t, p.shouldReset(5*time.Second, p.initialBackOff))
+	assert.True(t, p.shouldReset(11*time.Second, p.initialBackOff))
+
+	for i := 0; i < 100; i++ {
+		backOff := p.withJitter(1 * time.Second)
+		assert.GreaterOrEqual(t, backOff, 500*time.Millisecond)
+		assert.LessOrEqual(t, backOff, 1500*time.Millisecond)
+	}
+}
+
+func TestInvalidRestartPolicyIsRejected(t *testing.T) {
+	_, err := newRestartPolicy(&config.JobConfig{Restart: &config.Restart{Policy: "sometimes"}})
+	assert.Error(t, err)
+
+	_, err = newRestartPolicy(&config.JobConfig{Restart: &config.Restart{Jitter: 2}})
+	assert.Error(t, err)
+
+	_, err = newRestartPolicy(&config.JobConfig{OneTime: true, Restart: &config.Restart{Policy: config.RestartPolicyAlways}})
+	assert.Error(t, err)
+
+	_, err = newRestartPolicy(&config.JobConfig{Restart: &config.Restart{InitialBackoff: "0s"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package proc
+
+import (
+	"time"
+
+	"github.com/mittwald/mittnite/pkg/probe"
+)
+
+// number of events that are kept in memory for clients that connect later
+const eventHistorySize = 100
+
+type EventType string
+
+const (
+	EventTypeJobPhase     EventType = "jobPhase"
+	EventTypeProbe        EventType = "probe"
+	EventTypeFileWatch    EventType = "fileWatch"
+	EventTypeLazySpinUp   EventType = "lazySpinUp"
+	EventTypeLazyCoolDown EventType = "lazyCoolDown"
+)
+
+// Event describes a state change of a job or probe. Depending on the type,
+// only some of the fields are set.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type EventType `json:"type"`
+	Job  string    `json:"job,omitempty"`
+
+	// jobPhase events
+	Phase         JobPhaseReason `json:"phase,omitempty"`
+	PreviousPhase JobPhaseReason `json:"previousPhase,omitempty"`
+
+	// probe events; for readiness and liveness probes, Probe is the job name
+	Probe     string `json:"probe,omitempty"`
+	ProbeKind string `json:"probeKind,omitempty"`
+	Healthy   *bool  `json:"healthy,omitempty"`
+
+	// fileWatch events
+	File string `json:"file,omitempty"`
+
+	Message string `json:"message,omitempty"`
+}
+
+// OnProbeStateChange publishes state changes of probes as events; it needs to
+// be registered with probe.OnStateChange.
+func (r *Runner) OnProbeStateChange(name, kind string, healthy bool, err error) {
+	e := Event{
+		Type:      EventTypeProbe,
+		Probe:     name,
+		ProbeKind: kind,
+		Healthy:   &healthy,
+	}
+	if kind != probe.KindProbe {
+		e.Job = name
+	}
+	if err != nil {
+		e.Message = err.Error()
+	}
+	publishEvent(r.events, e)
+}
+
+// publishEvent adds the event to the given event history. Jobs that have not
+// been created by a runner have no event history; their events are dropped.
+func publishEvent(events *ringBuffer[Event], e Event) {
+	if events == nil {
+		return
+	}
+
+	e.Time = time.Now()
+	events.Add(e)
+}
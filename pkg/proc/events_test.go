@@ -0,0 +1,60 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/mittwald/mittnite/pkg/probe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type probeFunc func() error
+
+func (f probeFunc) Exec() error {
+	return f()
+}
+
+func TestStateChangesArePublishedAsEvents(t *testing.T) {
+	runner := NewRunner(context.Background(), nil, false, &config.Ignition{})
+	probe.OnStateChange(runner.OnProbeStateChange)
+	defer probe.OnStateChange(nil)
+
+	_, eventChan, unsubscribe := runner.events.Subscribe(0)
+	defer unsubscribe()
+
+	next := func() Event {
+		return <-eventChan
+	}
+
+	phase := JobPhase{job: "event-test", events: runner.events}
+	phase.Set(JobPhaseReasonStarted)
+	phase.Set(JobPhaseReasonStarted)
+	phase.Set(JobPhaseReasonReady)
+
+	assert.Equal(t, JobPhaseReasonStarted, next().Phase)
+	e := next()
+	assert.Equal(t, EventTypeJobPhase, e.Type)
+	assert.Equal(t, "event-test", e.Job)
+	assert.Equal(t, JobPhaseReasonStarted, e.PreviousPhase)
+	assert.Equal(t, JobPhaseReasonReady, e.Phase)
+
+	var probeErr error
+	p := probe.Instrument(probeFunc(func() error { return probeErr }), "event-test", probe.KindReadiness)
+	_ = p.Exec()
+	_ = p.Exec()
+	probeErr = errors.New("connection refused")
+	_ = p.Exec()
+
+	e = next()
+	assert.Equal(t, EventTypeProbe, e.Type)
+	require.NotNil(t, e.Healthy)
+	assert.True(t, *e.Healthy)
+
+	e = next()
+	require.NotNil(t, e.Healthy)
+	assert.False(t, *e.Healthy)
+	assert.Equal(t, "connection refused", e.Message)
+}
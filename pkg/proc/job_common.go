@@ -2,11 +2,10 @@ package proc
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -14,14 +13,9 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-const (
-	// longest duration between two restarts
-	maxBackOff = 300 * time.Second
-)
-
 func (job *CommonJob) Init() {
-	job.restart = false
-	job.stop = false
+	job.restart.Store(false)
+	job.stop.Store(false)
 
 	for w := range job.Config.Watches {
 		watch := &job.Config.Watches[w]
@@ -44,37 +38,55 @@ func (job *CommonJob) Init() {
 }
 
 func (job *CommonJob) Run(ctx context.Context, _ chan<- error) error {
-	if job.stop {
+	if job.stop.Load() {
 		return nil
 	}
 
+	if job.schedule != nil {
+		return job.runScheduled(ctx)
+	}
+
 	l := log.WithField("job.name", job.Config.Name)
 
-	backOff := 1 * time.Second
+	backOff := job.restartPolicy.initialBackOff
 	attempts := 0
+	isFirstStart := true
 	maxAttempts := job.Config.GetMaxAttempts()
 
-	p := make(chan *os.Process)
-	defer close(p)
+	checksCtx, cancelChecks := context.WithCancel(ctx)
+	defer cancelChecks()
 
-	go func() {
-		for range p {
-			job.phase.Set(JobPhaseReasonStarted)
-		}
-	}()
+	if job.readinessProbe != nil {
+		go job.watchReadiness(checksCtx)
+	}
+
+	if job.livenessProbe != nil {
+		go job.watchLiveness(checksCtx)
+	}
 
 	for { // restart failed jobs as long mittnite is running
-		if job.stop {
+		if job.stop.Load() || ctx.Err() != nil {
 			return nil
 		}
 
-		job.ctx, job.interrupt = context.WithCancel(context.Background())
+		if !isFirstStart {
+			atomic.AddUint64(&job.restarts, 1)
+		}
+		isFirstStart = false
+
+		job.newRunContext()
 		startedAt := time.Now()
-		err := job.startOnce(ctx, p)
+		err := job.startOnceAndMarkStarted(ctx)
+		if ctx.Err() != nil {
+			l.Info("job stopped due to shutdown")
+			job.phase.Set(JobPhaseReasonStopped)
+			return nil
+		}
+
 		switch err {
 		case nil:
-			if job.Config.OneTime {
-				l.Info("one-time job has ended successfully")
+			if !job.restartPolicy.restartOnSuccess() {
+				l.Info("job has ended successfully")
 				job.phase.Set(JobPhaseReasonCompleted)
 				return nil
 			}
@@ -88,29 +100,46 @@ func (job *CommonJob) Run(ctx context.Context, _ chan<- error) error {
 			return nil
 		}
 
-		if time.Since(startedAt) > backOff {
-			backOff = 1 * time.Second
+		if job.restartPolicy.shouldReset(time.Since(startedAt), backOff) {
+			backOff = job.restartPolicy.initialBackOff
 			attempts = 0
 		}
 
-		attempts++
-		if maxAttempts == -1 || attempts < maxAttempts {
-			currBackOff := backOff
-			backOff = calculateNextBackOff(currBackOff, maxBackOff)
+		currBackOff := job.restartPolicy.withJitter(backOff)
+		backOff = job.restartPolicy.nextBackOff(backOff)
 
-			job.phase.Set(JobPhaseReasonCrashLooping)
-			l.
-				WithField("job.maxAttempts", maxAttempts).
-				WithField("job.usedAttempts", attempts).
-				WithField("job.nextRestartIn", currBackOff.String()).
-				Info("remaining attempts")
-
-			job.crashLoopSleep(currBackOff)
+		// successful exits do not count as failed attempts
+		if err == nil {
+			l.WithField("job.nextRestartIn", currBackOff.String()).Info("restarting job")
+			job.crashLoopSleep(ctx, currBackOff)
 			continue
 		}
 
+		if job.restartPolicy.restartOnFailure() {
+			attempts++
+			if maxAttempts == -1 || attempts < maxAttempts {
+				job.phase.Set(JobPhaseReasonCrashLooping)
+				l.
+					WithField("job.maxAttempts", maxAttempts).
+					WithField("job.usedAttempts", attempts).
+					WithField("job.nextRestartIn", currBackOff.String()).
+					Info("remaining attempts")
+
+				job.crashLoopSleep(ctx, currBackOff)
+				continue
+			}
+		}
+
 		job.phase.Set(JobPhaseReasonFailed)
 
+		if !job.restartPolicy.restartOnFailure() {
+			if job.Config.CanFail {
+				l.WithError(err).Warn("job failed and will not be restarted")
+				return nil
+			}
+			return fmt.Errorf("job %s failed and will not be restarted: %w", job.Config.Name, err)
+		}
+
 		if job.Config.CanFail {
 			l.WithField("job.maxAttempts", maxAttempts).Warn("reached max retries")
 			return nil
@@ -120,6 +149,28 @@ func (job *CommonJob) Run(ctx context.Context, _ chan<- error) error {
 	}
 }
 
+// startOnceAndMarkStarted starts the job's process like startOnceWithHooks,
+// and sets the job's phase to started as soon as the process is running. The
+// phase is set before this returns, so that it cannot overwrite the phase set
+// after the process has exited.
+func (job *CommonJob) startOnceAndMarkStarted(ctx context.Context) error {
+	p := make(chan *os.Process)
+	marked := make(chan struct{})
+
+	go func() {
+		defer close(marked)
+		for range p {
+			job.phase.Set(JobPhaseReasonStarted)
+		}
+	}()
+
+	err := job.startOnceWithHooks(ctx, p)
+	close(p)
+	<-marked
+
+	return err
+}
+
 func (job *CommonJob) Watch() {
 	for w := range job.Config.Watches {
 		watch := &job.Config.Watches[w]
@@ -145,6 +196,7 @@ func (job *CommonJob) Watch() {
 			log.Infof("file %s changed, signalling process %s", p, job.Config.Name)
 			job.watchingFiles[p] = mtime
 			signal = true
+			publishEvent(job.events, Event{Type: EventTypeFileWatch, Job: job.Config.Name, File: p, Message: "file changed"})
 		}
 
 		// check deleted files
@@ -154,6 +206,7 @@ func (job *CommonJob) Watch() {
 				log.Infof("file %s not found, signalling process %s", p, job.Config.Name)
 				delete(job.watchingFiles, p)
 				signal = true
+				publishEvent(job.events, Event{Type: EventTypeFileWatch, Job: job.Config.Name, File: p, Message: "file deleted"})
 			}
 		}
 
@@ -182,64 +235,73 @@ func (job *CommonJob) Watch() {
 	}
 }
 
+func (job *CommonJob) GetDependencies() []string {
+	return job.Config.DependsOn
+}
+
 func (job *CommonJob) IsRunning() bool {
-	if job.cmd == nil {
+	cmd, _ := job.command()
+	if cmd == nil {
 		return false
 	}
-	if job.cmd.Process == nil {
+	if cmd.Process == nil {
 		return false
 	}
-	if job.cmd.Process.Pid > 0 {
-		return syscall.Kill(job.cmd.Process.Pid, syscall.Signal(0)) == nil
+	if cmd.Process.Pid > 0 {
+		return syscall.Kill(cmd.Process.Pid, syscall.Signal(0)) == nil
 	}
 	return true
 }
 
 func (job *CommonJob) Restart() {
-	job.restart = true
-	job.SignalAll(syscall.SIGTERM)
-	job.interrupt()
+	job.restart.Store(true)
+	job.terminate()
+	job.interruptRun()
 }
 
 func (job *CommonJob) Stop() {
-	job.stop = true
-	job.SignalAll(syscall.SIGTERM)
-	job.interrupt()
+	job.stop.Store(true)
+	job.terminate()
+	job.interruptRun()
 }
 
 func (job *CommonJob) Status() *CommonJobStatus {
 	running := job.IsRunning()
 	var pid int
-	if running {
-		pid = job.cmd.Process.Pid
+	if cmd, _ := job.command(); running {
+		pid = cmd.Process.Pid
 	}
-	return &CommonJobStatus{
-		Pid:     pid,
-		Running: job.IsRunning(),
-		Phase:   job.phase,
-		Config:  job.Config,
+	var schedule *ScheduleStatus
+	if job.schedule != nil {
+		job.statusLock.Lock()
+		schedule = &ScheduleStatus{
+			NextRun: job.nextRun,
+			LastRun: job.lastRun,
+		}
+		job.statusLock.Unlock()
 	}
-}
 
-func (job *CommonJob) executeWatchCommand(watchCmd *config.WatchCommand) error {
-	if len(watchCmd.Command) == 0 {
-		return errors.New("command is missing")
-	}
-	cmd := exec.Command(watchCmd.Command, watchCmd.Args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Env = os.Environ()
+	job.statusLock.Lock()
+	lastRestartReason := job.lastRestartReason
+	job.statusLock.Unlock()
 
-	if watchCmd.Env != nil {
-		cmd.Env = append(cmd.Env, watchCmd.Env...)
+	return &CommonJobStatus{
+		Pid:               pid,
+		Running:           running,
+		Phase:             job.phase.Get(),
+		LastRestartReason: lastRestartReason,
+		Schedule:          schedule,
+		Config:            job.Config.Redacted(),
 	}
+}
 
+func (job *CommonJob) executeWatchCommand(watchCmd *config.WatchCommand) error {
 	log.WithField("job.name", job.Config.Name).
 		Info("executing watch command")
-	return cmd.Run()
+	return job.executeCommand(context.Background(), watchCmd)
 }
 
-func (job *CommonJob) crashLoopSleep(duration time.Duration) {
+func (job *CommonJob) crashLoopSleep(ctx context.Context, duration time.Duration) {
 	timeout := make(chan bool)
 
 	go func() {
@@ -254,6 +316,8 @@ func (job *CommonJob) crashLoopSleep(duration time.Duration) {
 			return
 		case <-job.ctx.Done():
 			return
+		case <-ctx.Done():
+			return
 		}
 	}
 }
@@ -0,0 +1,117 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultHookTimeout = 30 * time.Second
+
+// startOnceWithHooks starts the job's process like startOnce, but runs the
+// job's lifecycle hooks around it. A failing preStart hook prevents the
+// process from being started and is returned as error.
+func (job *CommonJob) startOnceWithHooks(ctx context.Context, process chan<- *os.Process) error {
+	l := log.WithField("job.name", job.Config.Name)
+
+	if err := job.runHook(ctx, "preStart", job.Config.PreStart); err != nil {
+		return fmt.Errorf("preStart hook failed: %w", err)
+	}
+
+	started := process
+	postStartDone := make(chan struct{})
+	if job.Config.PostStart != nil {
+		startedWithHook := make(chan *os.Process)
+		started = startedWithHook
+
+		go func() {
+			defer close(postStartDone)
+			for p := range startedWithHook {
+				if process != nil {
+					process <- p
+				}
+				if err := job.runHook(ctx, "postStart", job.Config.PostStart); err != nil {
+					l.WithError(err).Warn("postStart hook failed")
+				}
+			}
+		}()
+	} else {
+		close(postStartDone)
+	}
+
+	previousCmd, _ := job.command()
+	err := job.startOnce(ctx, started)
+
+	// the postStop hook must not run before the postStart hook has finished
+	if started != process {
+		close(started)
+	}
+	<-postStartDone
+
+	// the postStop hook is only executed if the process was actually started
+	if cmd, _ := job.command(); cmd != previousCmd {
+		// use a fresh context, so that the hook also runs on shutdown
+		if hookErr := job.runHook(context.Background(), "postStop", job.Config.PostStop); hookErr != nil {
+			l.WithError(hookErr).Warn("postStop hook failed")
+		}
+	}
+
+	return err
+}
+
+// runPreStopHook is executed before the job's process is signalled to stop.
+func (job *CommonJob) runPreStopHook() {
+	if err := job.runHook(context.Background(), "preStop", job.Config.PreStop); err != nil {
+		log.WithField("job.name", job.Config.Name).WithError(err).Warn("preStop hook failed")
+	}
+}
+
+func (job *CommonJob) runHook(ctx context.Context, name string, hook *config.HookCommand) error {
+	if hook == nil {
+		return nil
+	}
+
+	timeout := defaultHookTimeout
+	if hook.Timeout != "" {
+		t, err := time.ParseDuration(hook.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+		timeout = t
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	log.WithField("job.name", job.Config.Name).
+		WithField("job.hook", name).
+		Info("executing hook")
+
+	err := job.executeCommand(ctx, &hook.WatchCommand)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("hook timed out after %s", timeout)
+	}
+	return err
+}
+
+func (job *CommonJob) executeCommand(ctx context.Context, c *config.WatchCommand) error {
+	if len(c.Command) == 0 {
+		return errors.New("command is missing")
+	}
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+
+	if c.Env != nil {
+		cmd.Env = append(cmd.Env, c.Env...)
+	}
+
+	return cmd.Run()
+}
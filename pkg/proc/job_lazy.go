@@ -4,7 +4,6 @@ import (
 	"context"
 	"os"
 	"sync"
-	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -31,7 +30,7 @@ func (job *LazyJob) AssertStarted(ctx context.Context) error {
 	e := make(chan error)
 
 	go func() {
-		if err := job.startOnce(ctx, p); err != nil {
+		if err := job.startOnceWithHooks(ctx, p); err != nil {
 			l.WithError(err).Error("process terminated with error")
 
 			select {
@@ -51,6 +50,7 @@ func (job *LazyJob) AssertStarted(ctx context.Context) error {
 	case err := <-e:
 		return err
 	case job.process = <-p:
+		publishEvent(job.events, Event{Type: EventTypeLazySpinUp, Job: job.Config.Name})
 		return nil
 	}
 }
@@ -68,7 +68,7 @@ func (job *LazyJob) Run(ctx context.Context, errors chan<- error) error {
 		listenerWaitGroup.Add(1)
 
 		go func() {
-			if err := listener.Run(ctx); err != nil {
+			if err := listener.Run(ctx); err != nil && ctx.Err() == nil {
 				log.WithError(err).Error("listener stopped with error")
 				errors <- err
 			}
@@ -78,6 +78,7 @@ func (job *LazyJob) Run(ctx context.Context, errors chan<- error) error {
 	}
 
 	job.startProcessReaper(ctx)
+	job.phase.Set(JobPhaseReasonAwaitingConnection)
 
 	log.Infof("holding off starting job %s until first request", job.Config.Name)
 	return nil
@@ -106,7 +107,8 @@ func (job *LazyJob) startProcessReaper(ctx context.Context) {
 
 				job.lazyStartLock.Lock()
 
-				job.Signal(syscall.SIGTERM)
+				job.terminate()
+				publishEvent(job.events, Event{Type: EventTypeLazyCoolDown, Job: job.Config.Name})
 
 				job.lazyStartLock.Unlock()
 			}
@@ -58,11 +58,24 @@ func (l *Listener) Run(ctx context.Context) error {
 	case err := <-runErrors:
 		return err
 	case <-ctx.Done():
+		// close the socket before returning, so that the address can be
+		// reused immediately (e.g. after a configuration reload)
+		if err := l.socket.Close(); err != nil {
+			log.WithField("reason", err.Error()).Warn("cannot reliably close socket")
+		}
 		return errors.New("context closed")
 	}
 }
 
 func (l *Listener) provideUpstreamConnection() (net.Conn, error) {
+	conn, err := net.Dial(getProto(l.config.ForwardProtocol), l.config.Forward)
+	if err == nil {
+		return conn, nil
+	}
+
+	// the process is not accepting connections yet; it is probably still
+	// spinning up
+	start := time.Now()
 	timeout := time.NewTimer(l.spinUpTimeout)
 	ticker := time.NewTicker(20 * time.Millisecond)
 
@@ -74,6 +87,7 @@ func (l *Listener) provideUpstreamConnection() (net.Conn, error) {
 		case <-ticker.C:
 			conn, err := net.Dial(getProto(l.config.ForwardProtocol), l.config.Forward)
 			if err == nil {
+				lazyJobSpinUpDuration.WithLabelValues(l.job.Config.Name).Observe(time.Since(start).Seconds())
 				return conn, nil
 			}
 		case <-timeout.C:
@@ -83,7 +97,7 @@ func (l *Listener) provideUpstreamConnection() (net.Conn, error) {
 }
 
 func (l *Listener) run(ctx context.Context) <-chan error {
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
 
 	go func() {
 		for {
@@ -100,10 +114,7 @@ func (l *Listener) run(ctx context.Context) <-chan error {
 			select {
 			case <-ctx.Done():
 				// received sigterm before new connection could have been established,
-				// we are about to shut down, close socket and listener and return
-				if err := l.socket.Close(); err != nil {
-					log.WithField("reason", err.Error()).Warn("cannot reliably close socket")
-				}
+				// we are about to shut down; the socket is closed by Run
 				return
 			case ar := <-connChan:
 				if ar.err != nil {
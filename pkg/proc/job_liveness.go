@@ -0,0 +1,60 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// watchLiveness periodically executes the job's liveness check while the
+// process is running, and restarts the process after the configured number of
+// consecutive failures.
+func (job *CommonJob) watchLiveness(ctx context.Context) {
+	l := log.WithField("job.name", job.Config.Name)
+
+	ticker := time.NewTicker(job.livenessInterval)
+	defer ticker.Stop()
+
+	failures := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !job.phase.Is(JobPhaseReasonStarted) && !job.phase.Is(JobPhaseReasonReady) {
+			failures = 0
+			continue
+		}
+
+		err := job.livenessProbe.Exec()
+		if err == nil {
+			failures = 0
+			continue
+		}
+
+		failures++
+		l.
+			WithError(err).
+			WithField("job.livenessFailures", failures).
+			WithField("job.livenessFailureThreshold", job.livenessFailureThreshold).
+			Warn("liveness check failed")
+
+		if failures < job.livenessFailureThreshold {
+			continue
+		}
+
+		failures = 0
+		reason := fmt.Sprintf("liveness check failed %d times in a row: %s", job.livenessFailureThreshold, err.Error())
+		job.statusLock.Lock()
+		job.lastRestartReason = reason
+		job.statusLock.Unlock()
+
+		l.WithField("job.restartReason", reason).Warn("restarting job")
+		job.Restart()
+	}
+}
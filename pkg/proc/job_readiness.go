@@ -0,0 +1,93 @@
+package proc
+
+import (
+	"context"
+	"time"
+
+	"github.com/mittwald/mittnite/pkg/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+var _ probe.JobReadinessSource = &Runner{}
+
+// watchReadiness periodically executes the job's readiness check while the
+// process is running, and toggles the job phase between "started" and "ready"
+// depending on the result.
+func (job *CommonJob) watchReadiness(ctx context.Context) {
+	l := log.WithField("job.name", job.Config.Name)
+
+	ticker := time.NewTicker(job.readinessInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !job.phase.Is(JobPhaseReasonStarted) && !job.phase.Is(JobPhaseReasonReady) {
+			continue
+		}
+
+		err := job.readinessProbe.Exec()
+		job.statusLock.Lock()
+		job.lastReadinessError = err
+		job.statusLock.Unlock()
+
+		switch {
+		case err == nil && job.phase.Is(JobPhaseReasonStarted):
+			l.Info("job is ready")
+			job.phase.Set(JobPhaseReasonReady)
+		case err != nil && job.phase.Is(JobPhaseReasonReady):
+			l.WithError(err).Warn("job is not ready anymore")
+			job.phase.Set(JobPhaseReasonStarted)
+		case err != nil:
+			l.WithError(err).Debug("job is not ready yet")
+		}
+	}
+}
+
+// IsReady returns true if the job has passed its readiness check. Jobs without
+// a readiness check are ready as soon as they have been started.
+func (job *CommonJob) IsReady() bool {
+	if job.readinessProbe == nil {
+		return job.phase.Is(JobPhaseReasonStarted)
+	}
+	return job.phase.Is(JobPhaseReasonReady)
+}
+
+// JobReadiness returns the readiness of all jobs that have a readiness check
+// configured; it implements probe.JobReadinessSource.
+func (r *Runner) JobReadiness() map[string]*probe.ProbeResult {
+	result := make(map[string]*probe.ProbeResult)
+
+	for _, job := range r.jobsSnapshot() {
+		commonJob, ok := job.(*CommonJob)
+		if !ok || commonJob.readinessProbe == nil {
+			continue
+		}
+
+		jobResult := &probe.ProbeResult{
+			Name: commonJob.Config.Name,
+			OK:   commonJob.IsReady(),
+		}
+
+		commonJob.statusLock.Lock()
+		lastReadinessError := commonJob.lastReadinessError
+		commonJob.statusLock.Unlock()
+
+		phase := commonJob.phase.Get()
+		switch {
+		case jobResult.OK:
+		case lastReadinessError != nil && phase.Reason == JobPhaseReasonStarted:
+			jobResult.Message = lastReadinessError.Error()
+		default:
+			jobResult.Message = "job is " + string(phase.Reason)
+		}
+
+		result[commonJob.Config.Name] = jobResult
+	}
+
+	return result
+}
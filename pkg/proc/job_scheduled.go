@@ -0,0 +1,170 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// runScheduled runs the job every time its schedule is due, until the context
+// is cancelled or the job is stopped. Only one run is active at any time;
+// what happens when a run is due while the previous one is still active is
+// determined by the job's overlap policy.
+func (job *CommonJob) runScheduled(ctx context.Context) error {
+	l := log.WithField("job.name", job.Config.Name)
+
+	job.newRunContext()
+
+	var (
+		running   bool
+		cancelRun context.CancelFunc
+		queued    int // number of runs to start after the active one; at most 1
+	)
+
+	runDone := make(chan *ScheduledRunResult)
+
+	startRun := func() {
+		var (
+			runCtx context.Context
+			cancel context.CancelFunc
+		)
+		if job.scheduleTimeout > 0 {
+			runCtx, cancel = context.WithTimeout(ctx, job.scheduleTimeout)
+		} else {
+			runCtx, cancel = context.WithCancel(ctx)
+		}
+
+		running = true
+		cancelRun = cancel
+
+		go func() {
+			defer cancel()
+			runDone <- job.runScheduledOnce(runCtx)
+		}()
+	}
+
+	nextRun := job.scheduleNextRun()
+	job.phase.Set(JobPhaseReasonScheduled)
+	l.WithField("job.nextRun", nextRun).Info("job is scheduled")
+
+	timer := time.NewTimer(time.Until(nextRun))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if running {
+				<-runDone
+			}
+			job.phase.Set(JobPhaseReasonStopped)
+			return nil
+
+		case <-job.ctx.Done():
+			if job.stop.Load() {
+				if running {
+					cancelRun()
+					<-runDone
+				}
+				l.Info("scheduled job stopped")
+				job.phase.Set(JobPhaseReasonStopped)
+				return nil
+			}
+
+			// the job has been restarted; the active run (if any) has
+			// already been signalled, so just keep the schedule going
+			job.restart.Store(false)
+			job.newRunContext()
+
+		case <-timer.C:
+			timer.Reset(time.Until(job.scheduleNextRun()))
+
+			if !running {
+				startRun()
+				continue
+			}
+
+			switch job.Config.Schedule.OverlapPolicy {
+			case config.OverlapPolicyQueue:
+				// like other cron implementations, queue at most one run, so
+				// that jobs that are slower than their schedule do not build
+				// up an ever-growing backlog
+				if queued > 0 {
+					l.Warn("previous run is still active and another run is already queued, skipping run")
+					continue
+				}
+				queued = 1
+				l.Info("previous run is still active, queueing run")
+			case config.OverlapPolicyReplace:
+				l.Info("previous run is still active, replacing it")
+				queued = 1
+				cancelRun()
+			default:
+				l.Warn("previous run is still active, skipping run")
+			}
+
+		case result := <-runDone:
+			running = false
+			job.statusLock.Lock()
+			job.lastRun = result
+			job.statusLock.Unlock()
+
+			if queued > 0 {
+				queued--
+				startRun()
+				continue
+			}
+
+			job.phase.Set(JobPhaseReasonScheduled)
+		}
+	}
+}
+
+// scheduleNextRun computes, records and returns the time of the job's next
+// run.
+func (job *CommonJob) scheduleNextRun() time.Time {
+	job.statusLock.Lock()
+	defer job.statusLock.Unlock()
+
+	job.nextRun = job.schedule.Next(time.Now())
+	return job.nextRun
+}
+
+func (job *CommonJob) runScheduledOnce(ctx context.Context) *ScheduledRunResult {
+	l := log.WithField("job.name", job.Config.Name)
+
+	result := ScheduledRunResult{
+		StartedAt: time.Now(),
+	}
+
+	job.phase.Set(JobPhaseReasonStarted)
+	err := job.startOnceWithHooks(ctx, nil)
+	result.FinishedAt = time.Now()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Error = fmt.Sprintf("run timed out after %s", job.scheduleTimeout)
+	case ctx.Err() != nil:
+		result.Error = "run was cancelled"
+	case err != nil:
+		result.Error = err.Error()
+	default:
+		result.Success = true
+	}
+
+	l.
+		WithField("job.runDuration", result.FinishedAt.Sub(result.StartedAt).String()).
+		WithField("job.runSuccess", result.Success).
+		Info("scheduled run finished")
+
+	return &result
+}
@@ -0,0 +1,12 @@
+package proc
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// jobs with resource limits re-execute the test binary as limits shim
+	HandleLimitsShim()
+	os.Exit(m.Run())
+}
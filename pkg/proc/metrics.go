@@ -0,0 +1,93 @@
+package proc
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	jobPhaseDesc = prometheus.NewDesc(
+		"mittnite_job_phase",
+		"Current phase of the job; 1 for the current phase, 0 for all others.",
+		[]string{"job", "phase"}, nil,
+	)
+	jobRestartsDesc = prometheus.NewDesc(
+		"mittnite_job_restarts_total",
+		"Number of times the job's process has been restarted.",
+		[]string{"job"}, nil,
+	)
+	jobUptimeDesc = prometheus.NewDesc(
+		"mittnite_job_uptime_seconds",
+		"Time since the job's current process has been started; only present while the process is running.",
+		[]string{"job"}, nil,
+	)
+	jobLastExitCodeDesc = prometheus.NewDesc(
+		"mittnite_job_last_exit_code",
+		"Exit code of the job's last process; -1 if it was terminated by a signal.",
+		[]string{"job"}, nil,
+	)
+	lazyJobActiveConnectionsDesc = prometheus.NewDesc(
+		"mittnite_lazy_job_active_connections",
+		"Number of currently active connections to a lazy job.",
+		[]string{"job"}, nil,
+	)
+
+	lazyJobSpinUpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mittnite_lazy_job_spin_up_duration_seconds",
+		Help:    "Time connections had to wait for a lazy job's process to accept connections.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"job"})
+)
+
+var _ prometheus.Collector = &Runner{}
+
+// Describe implements prometheus.Collector.
+func (r *Runner) Describe(ch chan<- *prometheus.Desc) {
+	ch <- jobPhaseDesc
+	ch <- jobRestartsDesc
+	ch <- jobUptimeDesc
+	ch <- jobLastExitCodeDesc
+	ch <- lazyJobActiveConnectionsDesc
+}
+
+// Collect implements prometheus.Collector; it reports the state of all
+// managed jobs.
+func (r *Runner) Collect(ch chan<- prometheus.Metric) {
+	for _, job := range r.jobsSnapshot() {
+		name := job.GetName()
+
+		phase := job.GetPhase().Get().Reason
+		for _, reason := range allJobPhaseReasons {
+			value := 0.0
+			if reason == phase {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(jobPhaseDesc, prometheus.GaugeValue, value, name, string(reason))
+		}
+
+		var commonJob *CommonJob
+		switch j := job.(type) {
+		case *CommonJob:
+			commonJob = j
+		case *LazyJob:
+			commonJob = &j.CommonJob
+			ch <- prometheus.MustNewConstMetric(lazyJobActiveConnectionsDesc, prometheus.GaugeValue, float64(atomic.LoadUint32(&j.activeConnections)), name)
+		default:
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(jobRestartsDesc, prometheus.CounterValue, float64(atomic.LoadUint64(&commonJob.restarts)), name)
+
+		startedAt, exitCode := commonJob.processStats()
+		if commonJob.IsRunning() {
+			ch <- prometheus.MustNewConstMetric(jobUptimeDesc, prometheus.GaugeValue, time.Since(startedAt).Seconds(), name)
+		}
+
+		if exitCode != nil {
+			ch <- prometheus.MustNewConstMetric(jobLastExitCodeDesc, prometheus.GaugeValue, float64(*exitCode), name)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package proc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunnerCollectsJobMetrics(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{BaseJobConfig: config.BaseJobConfig{Name: "sleeper", Command: "sleep", Args: []string{"10"}}},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	job := runner.findCommonJobByName("sleeper")
+	require.Eventually(t, func() bool {
+		return job.IsRunning()
+	}, 5*time.Second, 10*time.Millisecond, "job should be started")
+
+	job.Restart()
+	require.Eventually(t, func() bool {
+		_, exitCode := job.processStats()
+		return job.IsRunning() && exitCode != nil
+	}, 5*time.Second, 10*time.Millisecond, "job should be restarted")
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(runner))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	metrics := make(map[string][]*dto.Metric)
+	for _, family := range families {
+		metrics[family.GetName()] = family.GetMetric()
+	}
+
+	require.Len(t, metrics["mittnite_job_restarts_total"], 1)
+	assert.Equal(t, 1.0, metrics["mittnite_job_restarts_total"][0].GetCounter().GetValue())
+
+	require.Len(t, metrics["mittnite_job_last_exit_code"], 1)
+	assert.Equal(t, -1.0, metrics["mittnite_job_last_exit_code"][0].GetGauge().GetValue())
+
+	require.Len(t, metrics["mittnite_job_uptime_seconds"], 1)
+	assert.Greater(t, metrics["mittnite_job_uptime_seconds"][0].GetGauge().GetValue(), 0.0)
+
+	assert.Len(t, metrics["mittnite_job_phase"], len(allJobPhaseReasons))
+	for _, m := range metrics["mittnite_job_phase"] {
+		for _, label := range m.GetLabel() {
+			if label.GetName() == "phase" && label.GetValue() == string(JobPhaseReasonStarted) {
+				assert.Equal(t, 1.0, m.GetGauge().GetValue())
+			}
+		}
+	}
+
+	cancel()
+	runner.shutdown()
+}
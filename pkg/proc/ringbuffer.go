@@ -0,0 +1,100 @@
+package proc
+
+import (
+	"sync"
+)
+
+const (
+	// number of output lines kept in memory for jobs without output files
+	logBufferLines = 1000
+
+	// number of items that can be queued for a subscriber before items are
+	// dropped for it
+	ringBufferSubscriberQueue = 256
+)
+
+// ringBuffer keeps the last items added to it in memory, and passes new items
+// on to its subscribers.
+type ringBuffer[T any] struct {
+	lock        sync.Mutex
+	items       []T
+	next        int
+	full        bool
+	subscribers map[chan T]struct{}
+}
+
+// logBuffer keeps the last lines of a job's output, so that they can be
+// retrieved via the API when the job has no output files configured.
+type logBuffer = ringBuffer[[]byte]
+
+func newRingBuffer[T any](size int) *ringBuffer[T] {
+	return &ringBuffer[T]{
+		items:       make([]T, size),
+		subscribers: make(map[chan T]struct{}),
+	}
+}
+
+func newLogBuffer(size int) *logBuffer {
+	return newRingBuffer[[]byte](size)
+}
+
+// Add appends the given item to the buffer, overwriting the oldest item if
+// the buffer is full.
+func (b *ringBuffer[T]) Add(item T) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.items[b.next] = item
+	b.next = (b.next + 1) % len(b.items)
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub <- item:
+		default:
+			// the subscriber is too slow; drop the item rather than blocking
+			// the producer (e.g. the job's output)
+		}
+	}
+}
+
+// Tail returns the last n items of the buffer; all items if n is negative.
+func (b *ringBuffer[T]) Tail(n int) []T {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.tail(n)
+}
+
+func (b *ringBuffer[T]) tail(n int) []T {
+	var items []T
+	if b.full {
+		items = append(items, b.items[b.next:]...)
+	}
+	items = append(items, b.items[:b.next]...)
+
+	if n >= 0 && n < len(items) {
+		items = items[len(items)-n:]
+	}
+	return items
+}
+
+// Subscribe returns the last n items (see Tail) and a channel that receives
+// all items added afterwards, until unsubscribe is called.
+func (b *ringBuffer[T]) Subscribe(n int) (items []T, ch <-chan T, unsubscribe func()) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	sub := make(chan T, ringBufferSubscriberQueue)
+	b.subscribers[sub] = struct{}{}
+
+	unsubscribe = func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		delete(b.subscribers, sub)
+	}
+
+	return b.tail(n), sub, unsubscribe
+}
@@ -2,8 +2,10 @@ package proc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -13,12 +15,16 @@ import (
 
 func NewRunner(ctx context.Context, api *Api, keepRunning bool, ignitionConfig *config.Ignition) *Runner {
 	return &Runner{
-		IgnitionConfig: ignitionConfig,
-		ctx:            ctx,
-		jobs:           []Job{},
-		bootJobs:       make([]*BootJob, 0, len(ignitionConfig.BootJobs)),
-		api:            api,
-		keepRunning:    keepRunning,
+		IgnitionConfig:  ignitionConfig,
+		ctx:             ctx,
+		jobs:            []Job{},
+		bootJobs:        make([]*BootJob, 0, len(ignitionConfig.BootJobs)),
+		api:             api,
+		keepRunning:     keepRunning,
+		runningJobs:     make(map[string]*runningJob),
+		jobFingerprints: make(map[string]string),
+		dynamicJobs:     make(map[string]*config.JobConfig),
+		events:          newRingBuffer[Event](eventHistorySize),
 	}
 }
 
@@ -45,6 +51,7 @@ func (r *Runner) Boot() error {
 		if err != nil {
 			return err
 		}
+		job.setEvents(r.events)
 
 		r.bootJobs = append(r.bootJobs, job)
 	}
@@ -82,7 +89,9 @@ func (r *Runner) Boot() error {
 }
 
 func (r *Runner) Run() error {
+	r.reloadLock.Lock()
 	r.errChan = make(chan error)
+	r.shuttingDown = make(chan struct{})
 	r.waitGroup = &sync.WaitGroup{}
 	if r.keepRunning {
 		r.waitGroup.Add(1)
@@ -91,12 +100,16 @@ func (r *Runner) Run() error {
 	ticker := time.NewTicker(5 * time.Second)
 
 	r.exec()
+	r.reloadLock.Unlock()
 
 	wgChan := waitGroupToChannel(r.waitGroup)
 	for {
 		select {
 		case <-r.ctx.Done():
 			log.Warn("context cancelled")
+			r.reloadLock.Lock()
+			r.shutdown()
+			r.reloadLock.Unlock()
 			return r.ctx.Err()
 
 		// wait for them all to finish, or one to fail
@@ -110,6 +123,10 @@ func (r *Runner) Run() error {
 		// handle errors
 		case err := <-r.errChan:
 			log.Error(err)
+			r.reloadLock.Lock()
+			close(r.shuttingDown)
+			r.shutdown()
+			r.reloadLock.Unlock()
 			return err
 		}
 	}
@@ -117,7 +134,7 @@ func (r *Runner) Run() error {
 
 func (r *Runner) tick() {
 	log.Debugf("active goroutines: %d", runtime.NumGoroutine())
-	for _, job := range r.jobs {
+	for _, job := range r.jobsSnapshot() {
 		job.Watch()
 	}
 
@@ -125,6 +142,13 @@ func (r *Runner) tick() {
 		return
 	}
 
+	// jobs must not be restarted while the configuration is being changed;
+	// they are checked again on the next tick
+	if !r.reloadLock.TryLock() {
+		return
+	}
+	defer r.reloadLock.Unlock()
+
 	var toRestart []Job
 	for _, job := range r.jobs {
 		commonJob, ok := job.(*CommonJob)
@@ -132,44 +156,71 @@ func (r *Runner) tick() {
 			continue
 		}
 		phase := commonJob.GetPhase()
+		policy := commonJob.restartPolicy
 		switch {
-		case phase.Is(JobPhaseReasonFailed):
+		case phase.Is(JobPhaseReasonFailed) && policy.restartOnFailure():
 			toRestart = append(toRestart, job)
-		case phase.Is(JobPhaseReasonCompleted) && !commonJob.Config.OneTime:
+		case phase.Is(JobPhaseReasonCompleted) && policy.restartOnSuccess() && !commonJob.Config.OneTime:
 			toRestart = append(toRestart, job)
 		}
 	}
 
 	for _, job := range toRestart {
-		r.removeJob(job)
-		r.addJobIfNotExists(job)
-		r.startJob(job, JobPhaseReasonUnknown)
+		r.startJobAfterDependencies(job)
 	}
 }
 
 func (r *Runner) Init() error {
 	for j := range r.IgnitionConfig.Jobs {
-		var job Job
-		var err error
-
-		// init non-lazy jobs
-		if r.IgnitionConfig.Jobs[j].Laziness == nil {
-			job, err = NewCommonJob(&r.IgnitionConfig.Jobs[j])
-		} else {
-			job, err = NewLazyJob(&r.IgnitionConfig.Jobs[j])
+		fingerprint, err := jobConfigFingerprint(&r.IgnitionConfig.Jobs[j])
+		if err != nil {
+			return fmt.Errorf("error initializing job %s: %w", r.IgnitionConfig.Jobs[j].Name, err)
 		}
+
+		job, err := r.newJob(&r.IgnitionConfig.Jobs[j])
 		if err != nil {
 			return fmt.Errorf("error initializing job %s: %w", r.IgnitionConfig.Jobs[j].Name, err)
 		}
+
+		if r.findJobByName(job.GetName()) == nil {
+			r.jobFingerprints[job.GetName()] = fingerprint
+		}
 		r.addJobIfNotExists(job)
 	}
 
+	sortedJobs, err := sortJobsByDependencies(r.jobsSnapshot())
+	if err != nil {
+		return err
+	}
+	r.setJobs(sortedJobs)
+
 	return nil
 }
 
+// newJob creates a job from the given config, which publishes its events to
+// the runner's event history.
+func (r *Runner) newJob(c *config.JobConfig) (Job, error) {
+	// init non-lazy jobs
+	if c.Laziness == nil {
+		job, err := NewCommonJob(c)
+		if err != nil {
+			return nil, err
+		}
+		job.setEvents(r.events)
+		return job, nil
+	}
+
+	job, err := NewLazyJob(c)
+	if err != nil {
+		return nil, err
+	}
+	job.setEvents(r.events)
+	return job, nil
+}
+
 func (r *Runner) exec() {
 	for i := range r.jobs {
-		r.startJob(r.jobs[i], JobPhaseReasonUnknown)
+		r.startJobAfterDependencies(r.jobs[i])
 	}
 }
 
@@ -179,10 +230,13 @@ func (r *Runner) jobExistsAndIsControllable(job *CommonJob) bool {
 
 func (r *Runner) addAndStartJob(job Job) {
 	r.addJobIfNotExists(job)
-	r.startJob(job, job.GetPhase().Reason)
+	r.startJob(job, job.GetPhase().Get().Reason)
 }
 
 func (r *Runner) addJobIfNotExists(job Job) {
+	r.jobsLock.Lock()
+	defer r.jobsLock.Unlock()
+
 	for _, j := range r.jobs {
 		if j.GetName() == job.GetName() {
 			return
@@ -191,6 +245,23 @@ func (r *Runner) addJobIfNotExists(job Job) {
 	r.jobs = append(r.jobs, job)
 }
 
+// jobsSnapshot returns a copy of the managed jobs, which can be used without
+// holding any lock.
+func (r *Runner) jobsSnapshot() []Job {
+	r.jobsLock.RLock()
+	defer r.jobsLock.RUnlock()
+
+	return slices.Clone(r.jobs)
+}
+
+// setJobs replaces the managed jobs; the caller needs to hold reloadLock.
+func (r *Runner) setJobs(jobs []Job) {
+	r.jobsLock.Lock()
+	defer r.jobsLock.Unlock()
+
+	r.jobs = jobs
+}
+
 func (r *Runner) startJob(job Job, initialPhase JobPhaseReason) {
 	job.GetPhase().Set(initialPhase)
 	phase := job.GetPhase()
@@ -199,47 +270,119 @@ func (r *Runner) startJob(job Job, initialPhase JobPhaseReason) {
 	}
 
 	job.Init()
+	r.runJob(job, false)
+}
+
+// startJobAfterDependencies starts a job as soon as all of its dependencies
+// are satisfied.
+func (r *Runner) startJobAfterDependencies(job Job) {
+	if len(job.GetDependencies()) == 0 {
+		r.startJob(job, JobPhaseReasonUnknown)
+		return
+	}
+
+	job.GetPhase().Set(JobPhaseReasonAwaitingDependencies)
+	r.runJob(job, true)
+}
+
+func (r *Runner) runJob(job Job, awaitDependencies bool) {
+	// jobs are not bound to the runner's context directly; instead, they are
+	// cancelled one by one in reverse dependency order during shutdown.
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(r.ctx))
+	running := &runningJob{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	r.runningJobsLock.Lock()
+	r.runningJobs[job.GetName()] = running
+	r.runningJobsLock.Unlock()
 
 	r.waitGroup.Add(1)
 	go func() {
 		defer func() {
+			cancel()
+			close(running.done)
+
+			r.runningJobsLock.Lock()
+			if r.runningJobs[job.GetName()] == running {
+				delete(r.runningJobs, job.GetName())
+			}
+			r.runningJobsLock.Unlock()
+
 			r.waitGroup.Done()
 		}()
 
-		if err := job.Run(r.ctx, r.errChan); err != nil {
-			r.errChan <- err
+		if awaitDependencies {
+			if err := r.waitForDependencies(jobCtx, job); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.WithField("job.name", job.GetName()).WithError(err).Warn("job will not be started")
+					job.GetPhase().Set(JobPhaseReasonFailed)
+				}
+				return
+			}
+
+			job.GetPhase().Set(JobPhaseReasonUnknown)
+			job.Init()
+		}
+
+		if err := job.Run(jobCtx, r.errChan); err != nil {
+			select {
+			case r.errChan <- err:
+			case <-r.ctx.Done():
+				log.WithField("job.name", job.GetName()).WithError(err).Warn("job stopped with error during shutdown")
+			case <-r.shuttingDown:
+				log.WithField("job.name", job.GetName()).WithError(err).Warn("job stopped with error during shutdown")
+			}
 		}
 	}()
 }
 
-func (r *Runner) removeJob(job Job) {
-	for i, j := range r.jobs {
-		if j.GetName() == job.GetName() {
-			r.jobs[i] = r.jobs[len(r.jobs)-1]
-			r.jobs[len(r.jobs)-1] = nil
-			r.jobs = r.jobs[:len(r.jobs)-1]
-			return
-		}
+// stopJob stops a job that has been started by the runner and waits for it to
+// terminate.
+func (r *Runner) stopJob(job Job) {
+	r.runningJobsLock.Lock()
+	running, ok := r.runningJobs[job.GetName()]
+	delete(r.runningJobs, job.GetName())
+	r.runningJobsLock.Unlock()
+
+	if !ok {
+		return
 	}
+
+	running.cancel()
+	<-running.done
 }
 
-func (r *Runner) findCommonJobByName(name string) *CommonJob {
-	for i, job := range r.jobs {
+func (r *Runner) findJobByName(name string) Job {
+	r.jobsLock.RLock()
+	defer r.jobsLock.RUnlock()
+
+	for _, job := range r.jobs {
 		if job.GetName() == name {
-			commonJob, ok := r.jobs[i].(*CommonJob)
-			if !ok {
-				return nil
-			}
-			return commonJob
+			return job
 		}
 	}
 	return nil
 }
 
+func (r *Runner) findCommonJobByName(name string) *CommonJob {
+	commonJob, _ := r.findJobByName(name).(*CommonJob)
+	return commonJob
+}
+
 func (r *Runner) findCommonIgnitionJobByName(name string) (*CommonJob, error) {
+	r.jobsLock.RLock()
+	defer r.jobsLock.RUnlock()
+
 	for i, ignJob := range r.IgnitionConfig.Jobs {
 		if ignJob.Name == name && ignJob.Laziness == nil {
-			return NewCommonJob(&r.IgnitionConfig.Jobs[i])
+			job, err := NewCommonJob(&r.IgnitionConfig.Jobs[i])
+			if err != nil {
+				return nil, err
+			}
+			job.setEvents(r.events)
+			return job, nil
 		}
 	}
 	return nil, fmt.Errorf("can't find ignition config for job %q", name)
@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/mittwald/mittnite/internal/helper"
 	log "github.com/sirupsen/logrus"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -21,17 +24,28 @@ func (r *Runner) startAPIV1() error {
 		return nil
 	}
 
+	r.registerAPIV1Handlers()
+	return r.api.Start()
+}
+
+func (r *Runner) registerAPIV1Handlers() {
 	jobRouter := r.api.router.PathPrefix("/v1/job").Subrouter()
 	jobRouter.Use(r.apiV1JobMiddleware)
-	r.api.RegisterHandler(jobRouter, "/{job}/start", []string{http.MethodPost}, r.apiV1StartJob)
-	r.api.RegisterHandler(jobRouter, "/{job}/restart", []string{http.MethodPost}, r.apiV1RestartJob)
-	r.api.RegisterHandler(jobRouter, "/{job}/stop", []string{http.MethodPost}, r.apiV1StopJob)
-	r.api.RegisterHandler(jobRouter, "/{job}/status", []string{http.MethodGet}, r.apiV1JobStatus)
-	r.api.RegisterHandler(jobRouter, "/{job}/logs", []string{http.MethodGet}, r.apiV1JobLogs)
-
-	r.api.RegisterHandler(r.api.router, "/v1/jobs", []string{http.MethodGet}, r.apiV1JobList)
+	r.api.RegisterHandler(jobRouter, "/{job}/start", []string{http.MethodPost}, APIScopeControl, r.apiV1StartJob)
+	r.api.RegisterHandler(jobRouter, "/{job}/restart", []string{http.MethodPost}, APIScopeControl, r.apiV1RestartJob)
+	r.api.RegisterHandler(jobRouter, "/{job}/stop", []string{http.MethodPost}, APIScopeControl, r.apiV1StopJob)
+	r.api.RegisterHandler(jobRouter, "/{job}/signal", []string{http.MethodPost}, APIScopeControl, r.apiV1SignalJob)
+	r.api.RegisterHandler(jobRouter, "/{job}/status", []string{http.MethodGet}, APIScopeRead, r.apiV1JobStatus)
+	r.api.RegisterHandler(jobRouter, "/{job}/logs", []string{http.MethodGet}, APIScopeRead, r.apiV1JobLogs)
+	r.api.RegisterHandler(jobRouter, "/{job}", []string{http.MethodDelete}, APIScopeControl, r.apiV1DeleteJob)
 
-	return r.api.Start()
+	r.api.RegisterHandler(r.api.router, "/v1/jobs", []string{http.MethodGet}, APIScopeRead, r.apiV1JobList)
+	r.api.RegisterHandler(r.api.router, "/v1/jobs", []string{http.MethodPost}, APIScopeControl, r.apiV1CreateJob)
+	r.api.RegisterHandler(r.api.router, "/v1/config/reload", []string{http.MethodPost}, APIScopeControl, r.apiV1ReloadConfig)
+	r.api.RegisterHandler(r.api.router, "/v1/events", []string{http.MethodGet}, APIScopeRead, r.apiV1Events)
+	// probes are executed on request, so they require the control scope
+	r.api.RegisterHandler(r.api.router, "/v1/probes", []string{http.MethodGet}, APIScopeControl, r.apiV1ProbeList)
+	r.api.RegisterHandler(r.api.router, "/v1/probe/{probe}", []string{http.MethodGet}, APIScopeControl, r.apiV1ProbeStatus)
 }
 
 func (r *Runner) apiV1JobMiddleware(next http.Handler) http.Handler {
@@ -42,29 +56,48 @@ func (r *Runner) apiV1JobMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		job := r.findCommonJobByName(jobName)
-		if job == nil {
-			var err error
-			job, err = r.findCommonIgnitionJobByName(jobName)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
-				return
-			}
+		job, err := r.findOrAddControllableJob(jobName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		if r.jobExistsAndIsControllable(job) {
-			r.addJobIfNotExists(job)
-			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), contextKeyJob, job)))
+		if job == nil {
+			http.Error(w, fmt.Sprintf("job %q not found or is not controllable", jobName), http.StatusNotFound)
 			return
 		}
 
-		http.Error(w, fmt.Sprintf("job %q not found or is not controllable", jobName), http.StatusNotFound)
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), contextKeyJob, job)))
 	})
 }
 
+// findOrAddControllableJob returns the controllable job with the given name.
+// Jobs from the configuration that are not managed anymore (e.g. because they
+// have been deleted via api) are managed again.
+func (r *Runner) findOrAddControllableJob(name string) (*CommonJob, error) {
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	job := r.findCommonJobByName(name)
+	if job == nil {
+		var err error
+		job, err = r.findCommonIgnitionJobByName(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !r.jobExistsAndIsControllable(job) {
+		return nil, nil
+	}
+
+	r.addJobIfNotExists(job)
+	return job, nil
+}
+
 func (r *Runner) apiV1StartJob(writer http.ResponseWriter, req *http.Request) {
 	job := req.Context().Value(contextKeyJob).(*CommonJob)
-	if !job.IsRunning() {
-		r.startJob(job, JobPhaseReasonUnknown)
+	if !job.IsRunning() && !job.GetPhase().Is(JobPhaseReasonAwaitingDependencies) {
+		r.startJobAfterDependencies(job)
 	}
 	writer.WriteHeader(http.StatusOK)
 }
@@ -72,7 +105,9 @@ func (r *Runner) apiV1StartJob(writer http.ResponseWriter, req *http.Request) {
 func (r *Runner) apiV1RestartJob(writer http.ResponseWriter, req *http.Request) {
 	job := req.Context().Value(contextKeyJob).(*CommonJob)
 	if !job.IsRunning() {
-		r.startJob(job, JobPhaseReasonUnknown)
+		if !job.GetPhase().Is(JobPhaseReasonAwaitingDependencies) {
+			r.startJobAfterDependencies(job)
+		}
 	} else {
 		job.Restart()
 	}
@@ -85,6 +120,36 @@ func (r *Runner) apiV1StopJob(writer http.ResponseWriter, req *http.Request) {
 	writer.WriteHeader(http.StatusOK)
 }
 
+func (r *Runner) apiV1SignalJob(writer http.ResponseWriter, req *http.Request) {
+	job := req.Context().Value(contextKeyJob).(*CommonJob)
+
+	sigName := req.FormValue("sig")
+	if sigName == "" {
+		http.Error(writer, "sig parameter is missing", http.StatusBadRequest)
+		return
+	}
+
+	sig, err := helper.ParseSignal(sigName)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	group := strings.ToLower(req.FormValue("group")) == "true"
+
+	log.WithField("job.name", job.GetName()).WithField("group", group).Infof("sending signal %s via api", sig)
+	if err := job.SendSignal(sig, group); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrJobNotRunning) || errors.Is(err, syscall.ESRCH) {
+			status = http.StatusConflict
+		}
+		http.Error(writer, fmt.Sprintf("failed to send signal: %s", err.Error()), status)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
 func (r *Runner) apiV1JobStatus(writer http.ResponseWriter, req *http.Request) {
 	job := req.Context().Value(contextKeyJob).(*CommonJob)
 	out, err := json.Marshal(job.Status())
@@ -97,9 +162,57 @@ func (r *Runner) apiV1JobStatus(writer http.ResponseWriter, req *http.Request) {
 	writer.Write(out)
 }
 
+func (r *Runner) apiV1CreateJob(writer http.ResponseWriter, req *http.Request) {
+	if !r.api.jobCreation {
+		http.Error(writer, "creating jobs via api is disabled; start mittnite with --api-allow-job-creation to enable it", http.StatusForbidden)
+		return
+	}
+
+	var jobConfig config.JobConfig
+
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&jobConfig); err != nil {
+		http.Error(writer, fmt.Sprintf("invalid job configuration: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	// jobs added via api can only be managed via api
+	jobConfig.Controllable = true
+
+	if err := r.AddJob(&jobConfig); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrJobExists) {
+			status = http.StatusConflict
+		}
+		http.Error(writer, fmt.Sprintf("failed to create job: %s", err.Error()), status)
+		return
+	}
+
+	writer.WriteHeader(http.StatusCreated)
+}
+
+func (r *Runner) apiV1DeleteJob(writer http.ResponseWriter, req *http.Request) {
+	job := req.Context().Value(contextKeyJob).(*CommonJob)
+
+	if err := r.DeleteJob(job.GetName()); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrJobNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrJobIsRequired):
+			status = http.StatusConflict
+		}
+		http.Error(writer, fmt.Sprintf("failed to delete job: %s", err.Error()), status)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
 func (r *Runner) apiV1JobList(writer http.ResponseWriter, _ *http.Request) {
 	var jobs []string
-	for _, job := range r.jobs {
+	for _, job := range r.jobsSnapshot() {
 		commonJob, ok := job.(*CommonJob)
 		if !ok {
 			continue
@@ -119,6 +232,14 @@ func (r *Runner) apiV1JobList(writer http.ResponseWriter, _ *http.Request) {
 	writer.Write(out)
 }
 
+func (r *Runner) apiV1ReloadConfig(writer http.ResponseWriter, _ *http.Request) {
+	if err := r.Reload(); err != nil {
+		http.Error(writer, fmt.Sprintf("failed to reload configuration: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+}
+
 func (r *Runner) apiV1JobLogs(writer http.ResponseWriter, req *http.Request) {
 	conn, err := r.api.upgrader.Upgrade(writer, req, nil)
 	if err != nil {
@@ -132,14 +253,10 @@ func (r *Runner) apiV1JobLogs(writer http.ResponseWriter, req *http.Request) {
 	}()
 
 	job := req.Context().Value(contextKeyJob).(*CommonJob)
-	if len(job.Config.Stdout) == 0 && len(job.Config.Stderr) == 0 {
-		if err := conn.WriteMessage(websocket.TextMessage, []byte("neither stdout, nor stderr is defined for this job")); err != nil {
-			log.Printf("failed to write message: %v", err)
-		}
-		return
-	}
 
-	if !job.IsRunning() {
+	// the log buffer is kept across restarts, so its contents can also be
+	// shown when the job is not running
+	if !job.IsRunning() && job.logBuffer == nil {
 		_ = conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
 			"Failed to get logs for job %q: job is not running", job.GetName(),
 		)))
@@ -153,12 +270,7 @@ func (r *Runner) apiV1JobLogs(writer http.ResponseWriter, req *http.Request) {
 	outChan := make(chan []byte)
 	stdOutErrChan := make(chan error)
 	stdErrErrChan := make(chan error)
-	defer func() {
-		cancel()
-		close(outChan)
-		close(stdOutErrChan)
-		close(stdErrErrChan)
-	}()
+	defer cancel()
 
 	// handle client disconnects
 	go func() {
@@ -202,12 +314,84 @@ func (r *Runner) apiV1JobLogs(writer http.ResponseWriter, req *http.Request) {
 			}
 
 		case err := <-stdOutErrChan:
-			handleErr(err, &job.stdOutWg)
+			handleErr(err, job.stdOutWg)
 		case err := <-stdErrErrChan:
-			handleErr(err, &job.stdErrWg)
+			handleErr(err, job.stdErrWg)
 
 		case <-streamCtx.Done():
 			return
 		}
 	}
 }
+
+func (r *Runner) apiV1Events(writer http.ResponseWriter, req *http.Request) {
+	conn, err := r.api.upgrader.Upgrade(writer, req, nil)
+	if err != nil {
+		http.Error(writer, "failed to upgrade connection", http.StatusInternalServerError)
+		return
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Printf("failed to close connection: %v", err)
+		}
+	}()
+
+	follow := strings.ToLower(req.FormValue("follow")) == "true"
+	tailLen, err := strconv.Atoi(req.FormValue("taillen"))
+	if err != nil {
+		tailLen = -1
+	}
+
+	if !follow {
+		for _, event := range r.events.Tail(tailLen) {
+			if err := writeEvent(conn, event); err != nil {
+				return
+			}
+		}
+
+		_ = conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, "EOF"),
+			time.Now().Add(time.Second),
+		)
+		return
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// handle client disconnects
+	go func() {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			cancel()
+		}
+	}()
+
+	history, eventChan, unsubscribe := r.events.Subscribe(tailLen)
+	defer unsubscribe()
+
+	for _, event := range history {
+		if err := writeEvent(conn, event); err != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case event := <-eventChan:
+			if err := writeEvent(conn, event); err != nil {
+				return
+			}
+		case <-streamCtx.Done():
+			return
+		}
+	}
+}
+
+func writeEvent(conn *websocket.Conn, event Event) error {
+	out, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, out)
+}
@@ -0,0 +1,195 @@
+package proc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	dependencyPollInterval = 250 * time.Millisecond
+)
+
+var ErrDependencyFailed = errors.New("dependency has failed")
+
+// sortJobsByDependencies orders the given jobs so that each job is preceded by
+// all jobs it depends on. Jobs without any dependency relation keep their
+// original order. Unknown dependencies and dependency cycles are rejected.
+func sortJobsByDependencies(jobs []Job) ([]Job, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	jobsByName := make(map[string]Job, len(jobs))
+	for _, job := range jobs {
+		jobsByName[job.GetName()] = job
+	}
+
+	state := make(map[string]int, len(jobs))
+	sorted := make([]Job, 0, len(jobs))
+	var path []string
+
+	var visit func(job Job) error
+	visit = func(job Job) error {
+		name := job.GetName()
+
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for i := range path {
+				if path[i] == name {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, dependency := range job.GetDependencies() {
+			dependencyJob, ok := jobsByName[dependency]
+			if !ok {
+				return fmt.Errorf("job %q depends on unknown job %q", name, dependency)
+			}
+
+			if err := visit(dependencyJob); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+		sorted = append(sorted, job)
+
+		return nil
+	}
+
+	for _, job := range jobs {
+		if err := visit(job); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// dependencySatisfied returns true if dependent jobs may be started, which is
+// the case as soon as the job is ready (see CommonJob.IsReady). One-time jobs
+// need to have completed instead, and scheduled jobs only need to be
+// scheduled. Lazy jobs are considered satisfied as soon as they are awaiting
+// connections, because their listeners will hold incoming connections until
+// the process is up.
+func dependencySatisfied(job Job) bool {
+	phase := job.GetPhase()
+	if commonJob, ok := job.(*CommonJob); ok {
+		if commonJob.Config.Schedule != nil {
+			return phase.Is(JobPhaseReasonScheduled) || phase.Is(JobPhaseReasonStarted)
+		}
+		if commonJob.Config.OneTime {
+			return phase.Is(JobPhaseReasonCompleted)
+		}
+		return commonJob.IsReady()
+	}
+
+	return phase.Is(JobPhaseReasonStarted) || phase.Is(JobPhaseReasonAwaitingConnection)
+}
+
+// waitForDependencies blocks until all dependencies of the job are satisfied.
+// It fails if a dependency has failed; stopped dependencies are waited for,
+// as they might be started again via api.
+func (r *Runner) waitForDependencies(ctx context.Context, job Job) error {
+	l := log.WithField("job.name", job.GetName())
+
+	ticker := time.NewTicker(dependencyPollInterval)
+	defer ticker.Stop()
+
+	warnedStopped := make(map[string]bool)
+
+	for {
+		var pending []string
+		for _, dependency := range job.GetDependencies() {
+			dependencyJob := r.findJobByName(dependency)
+			if dependencyJob == nil {
+				return fmt.Errorf("job %q depends on unknown job %q", job.GetName(), dependency)
+			}
+
+			if dependencySatisfied(dependencyJob) {
+				delete(warnedStopped, dependency)
+				continue
+			}
+
+			switch phase := dependencyJob.GetPhase(); {
+			case phase.Is(JobPhaseReasonFailed):
+				return fmt.Errorf("%w: %s", ErrDependencyFailed, dependency)
+			case phase.Is(JobPhaseReasonStopped) && !warnedStopped[dependency]:
+				l.WithField("job.dependency", dependency).Warn("dependency has been stopped; waiting for it to be started again")
+				warnedStopped[dependency] = true
+			}
+
+			pending = append(pending, dependency)
+		}
+
+		if len(pending) == 0 {
+			l.Info("all dependencies are satisfied")
+			return nil
+		}
+
+		l.WithField("job.pendingDependencies", pending).Debug("waiting for dependencies")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// shutdown stops all running jobs in reverse dependency order; a job is only
+// stopped after all jobs depending on it have terminated.
+func (r *Runner) shutdown() {
+	r.runningJobsLock.Lock()
+	running := make(map[string]*runningJob, len(r.runningJobs))
+	for name, rj := range r.runningJobs {
+		running[name] = rj
+	}
+	r.runningJobsLock.Unlock()
+
+	dependents := make(map[string][]string)
+	for _, job := range r.jobs {
+		for _, dependency := range job.GetDependencies() {
+			dependents[dependency] = append(dependents[dependency], job.GetName())
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	for name, rj := range running {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for _, dependent := range dependents[name] {
+				if dependentJob, ok := running[dependent]; ok {
+					<-dependentJob.done
+				}
+			}
+
+			log.WithField("job.name", name).Info("stopping job")
+			rj.cancel()
+			<-rj.done
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,120 @@
+package proc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mittwald/mittnite/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrJobExists     = errors.New("job already exists")
+	ErrJobNotFound   = errors.New("job not found")
+	ErrJobIsRequired = errors.New("job is a dependency of other jobs")
+)
+
+// AddJob adds a job that is not part of the configuration directory and
+// starts it. Such jobs are kept when the configuration is reloaded, unless
+// the configuration directory now contains a job with the same name.
+func (r *Runner) AddJob(c *config.JobConfig) error {
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	if r.waitGroup == nil {
+		return errors.New("runner has not been started yet")
+	}
+
+	if c.Name == "" {
+		return errors.New("job has no name")
+	}
+	if errs := config.ValidateJob(c); len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, err := range errs {
+			messages[i] = err.Error()
+		}
+		return fmt.Errorf("invalid configuration of job %q: %s", c.Name, strings.Join(messages, "; "))
+	}
+	if r.findJobByName(c.Name) != nil {
+		return fmt.Errorf("%w: %s", ErrJobExists, c.Name)
+	}
+
+	fingerprint, err := jobConfigFingerprint(c)
+	if err != nil {
+		return fmt.Errorf("error initializing job %s: %w", c.Name, err)
+	}
+
+	job, err := r.newJob(c)
+	if err != nil {
+		return fmt.Errorf("error initializing job %s: %w", c.Name, err)
+	}
+
+	sortedJobs, err := sortJobsByDependencies(append(slices.Clone(r.jobs), job))
+	if err != nil {
+		return err
+	}
+
+	log.WithField("job.name", c.Name).Info("adding job")
+
+	r.setJobs(sortedJobs)
+	r.jobFingerprints[c.Name] = fingerprint
+	r.dynamicJobs[c.Name] = c
+	r.startJobAfterDependencies(job)
+
+	return nil
+}
+
+// DeleteJob stops a job and removes it from the managed jobs. Jobs from the
+// configuration directory are added again when the configuration is reloaded.
+func (r *Runner) DeleteJob(name string) error {
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	job := r.findJobByName(name)
+	if job == nil {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+
+	for _, other := range r.jobs {
+		if slices.Contains(other.GetDependencies(), name) {
+			return fmt.Errorf("%w: %s depends on %s", ErrJobIsRequired, other.GetName(), name)
+		}
+	}
+
+	log.WithField("job.name", name).Info("deleting job")
+
+	r.stopJob(job)
+
+	// keep the remaining jobs in dependency order
+	r.setJobs(slices.DeleteFunc(slices.Clone(r.jobs), func(j Job) bool {
+		return j.GetName() == name
+	}))
+	delete(r.jobFingerprints, name)
+	delete(r.dynamicJobs, name)
+
+	return nil
+}
+
+// jobConfigs returns the configurations of the jobs from the given
+// configuration and of the jobs added via AddJob.
+func (r *Runner) jobConfigs(ignitionConfig *config.Ignition) []*config.JobConfig {
+	configs := make([]*config.JobConfig, 0, len(ignitionConfig.Jobs)+len(r.dynamicJobs))
+	names := make(map[string]bool, len(ignitionConfig.Jobs))
+
+	for j := range ignitionConfig.Jobs {
+		configs = append(configs, &ignitionConfig.Jobs[j])
+		names[ignitionConfig.Jobs[j].Name] = true
+	}
+
+	for name, c := range r.dynamicJobs {
+		if names[name] {
+			log.WithField("job.name", name).Warn("job from the configuration directory replaces job that has been added via api")
+			continue
+		}
+		configs = append(configs, c)
+	}
+
+	return configs
+}
@@ -0,0 +1,61 @@
+package proc
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mittwald/mittnite/pkg/probe"
+)
+
+// probeCheckTimeout is the maximum duration a probe may take when it is
+// executed via the api.
+const probeCheckTimeout = 5 * time.Second
+
+// ProbeChecker executes the configured probes on demand.
+type ProbeChecker interface {
+	Check(name string, timeout time.Duration) (*probe.ProbeResult, bool)
+	CheckAll(timeout time.Duration) map[string]*probe.ProbeResult
+}
+
+// SetProbeChecker sets the source used to execute probes via the api.
+func (r *Runner) SetProbeChecker(probes ProbeChecker) {
+	r.probes = probes
+}
+
+func (r *Runner) apiV1ProbeList(writer http.ResponseWriter, _ *http.Request) {
+	if r.probes == nil {
+		http.Error(writer, "probes are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	writeProbeResponse(writer, r.probes.CheckAll(probeCheckTimeout))
+}
+
+func (r *Runner) apiV1ProbeStatus(writer http.ResponseWriter, req *http.Request) {
+	if r.probes == nil {
+		http.Error(writer, "probes are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	name := mux.Vars(req)["probe"]
+	result, ok := r.probes.Check(name, probeCheckTimeout)
+	if !ok {
+		http.Error(writer, "probe not found", http.StatusNotFound)
+		return
+	}
+
+	writeProbeResponse(writer, result)
+}
+
+func writeProbeResponse(writer http.ResponseWriter, body any) {
+	out, err := json.Marshal(body)
+	if err != nil {
+		http.Error(writer, "failed to encode probe results", http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(out)
+}
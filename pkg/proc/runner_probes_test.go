@@ -0,0 +1,62 @@
+package proc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mittwald/mittnite/pkg/probe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProbeChecker map[string]*probe.ProbeResult
+
+func (f fakeProbeChecker) Check(name string, _ time.Duration) (*probe.ProbeResult, bool) {
+	result, ok := f[name]
+	return result, ok
+}
+
+func (f fakeProbeChecker) CheckAll(_ time.Duration) map[string]*probe.ProbeResult {
+	return f
+}
+
+func TestProbesCanBeExecutedViaAPI(t *testing.T) {
+	r := &Runner{}
+	router := mux.NewRouter()
+	router.HandleFunc("/v1/probes", r.apiV1ProbeList)
+	router.HandleFunc("/v1/probe/{probe}", r.apiV1ProbeStatus)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, get("/v1/probes").Code)
+
+	r.SetProbeChecker(fakeProbeChecker{
+		"redis": {Name: "redis", OK: false, Message: "connection refused", Latency: 3 * time.Millisecond},
+	})
+
+	assert.Equal(t, http.StatusNotFound, get("/v1/probe/mysql").Code)
+
+	rec := get("/v1/probe/redis")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var result probe.ProbeResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.False(t, result.OK)
+	assert.Equal(t, "connection refused", result.Message)
+	assert.Equal(t, 3*time.Millisecond, result.Latency)
+
+	rec = get("/v1/probes")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var results map[string]*probe.ProbeResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	assert.Contains(t, results, "redis")
+}
@@ -0,0 +1,153 @@
+package proc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mittwald/mittnite/internal/config"
+	"github.com/mittwald/mittnite/pkg/files"
+	log "github.com/sirupsen/logrus"
+)
+
+// SetConfigDir sets the directory from which the configuration is re-read
+// when reloading.
+func (r *Runner) SetConfigDir(configDir string) {
+	r.configDir = configDir
+}
+
+// Reload re-reads the configuration directory, re-renders all files and
+// applies the new job configuration.
+func (r *Runner) Reload() error {
+	if r.configDir == "" {
+		return errors.New("no configuration directory set")
+	}
+
+	ignitionConfig := &config.Ignition{}
+	if err := ignitionConfig.GenerateFromConfigDir(r.configDir); err != nil {
+		return fmt.Errorf("failed while trying to generate ignition config from dir '%s': %w", r.configDir, err)
+	}
+
+	if err := files.RenderFiles(ignitionConfig.Files); err != nil {
+		return fmt.Errorf("failed while rendering files from ignition config, err: %w", err)
+	}
+
+	return r.ApplyConfig(ignitionConfig)
+}
+
+// ApplyConfig compares the jobs of the given configuration with the currently
+// managed jobs. New jobs are started, removed jobs are stopped and jobs whose
+// configuration changed are restarted; all other jobs keep running. If the new
+// configuration is invalid, nothing is changed.
+func (r *Runner) ApplyConfig(ignitionConfig *config.Ignition) error {
+	r.reloadLock.Lock()
+	defer r.reloadLock.Unlock()
+
+	if r.waitGroup == nil {
+		return errors.New("runner has not been started yet")
+	}
+
+	// prevent the runner from terminating while jobs are being replaced
+	r.waitGroup.Add(1)
+	defer r.waitGroup.Done()
+
+	jobConfigs := r.jobConfigs(ignitionConfig)
+	jobs := make([]Job, 0, len(jobConfigs))
+	fingerprints := make(map[string]string, len(jobConfigs))
+	replaced := make(map[string]Job)
+	toStart := make(map[string]bool)
+
+	for _, jobConfig := range jobConfigs {
+		if _, exists := fingerprints[jobConfig.Name]; exists {
+			continue
+		}
+
+		fingerprint, err := jobConfigFingerprint(jobConfig)
+		if err != nil {
+			return fmt.Errorf("error initializing job %s: %w", jobConfig.Name, err)
+		}
+		fingerprints[jobConfig.Name] = fingerprint
+
+		existing := r.findJobByName(jobConfig.Name)
+		if existing != nil && r.jobFingerprints[jobConfig.Name] == fingerprint {
+			jobs = append(jobs, existing)
+			continue
+		}
+
+		job, err := r.newJob(jobConfig)
+		if err != nil {
+			return fmt.Errorf("error initializing job %s: %w", jobConfig.Name, err)
+		}
+
+		if existing != nil {
+			replaced[jobConfig.Name] = existing
+		}
+
+		jobs = append(jobs, job)
+		toStart[jobConfig.Name] = true
+	}
+
+	sortedJobs, err := sortJobsByDependencies(jobs)
+	if err != nil {
+		return err
+	}
+
+	var removed []Job
+	for _, job := range r.jobs {
+		if _, ok := fingerprints[job.GetName()]; !ok {
+			removed = append(removed, job)
+		}
+	}
+
+	log.
+		WithField("jobs.added", len(toStart)-len(replaced)).
+		WithField("jobs.changed", len(replaced)).
+		WithField("jobs.removed", len(removed)).
+		Info("applying new configuration")
+
+	// stop jobs in reverse dependency order
+	for i := len(r.jobs) - 1; i >= 0; i-- {
+		job := r.jobs[i]
+		_, isReplaced := replaced[job.GetName()]
+		_, isKept := fingerprints[job.GetName()]
+		if !isReplaced && isKept {
+			continue
+		}
+
+		log.WithField("job.name", job.GetName()).Info("stopping job due to configuration change")
+		r.stopJob(job)
+	}
+
+	r.jobsLock.Lock()
+	r.jobs = sortedJobs
+	r.IgnitionConfig = ignitionConfig
+	r.jobsLock.Unlock()
+	r.jobFingerprints = fingerprints
+
+	for _, jobConfig := range ignitionConfig.Jobs {
+		delete(r.dynamicJobs, jobConfig.Name)
+	}
+
+	for _, job := range sortedJobs {
+		if toStart[job.GetName()] {
+			r.startJobAfterDependencies(job)
+		}
+	}
+
+	return nil
+}
+
+// jobConfigFingerprint returns a representation of the job configuration
+// that can be used to detect configuration changes. It needs to be computed
+// before the job is initialized, because initializing a job may modify its
+// configuration (e.g. by resolving environment variables).
+func jobConfigFingerprint(c *config.JobConfig) (string, error) {
+	fingerprint, err := json.Marshal(struct {
+		*config.JobConfig
+		MaxAttempts_ *int // excluded from the JSON representation of the config
+	}{c, c.MaxAttempts_})
+	if err != nil {
+		return "", err
+	}
+	return string(fingerprint), nil
+}
@@ -2,6 +2,10 @@ package proc
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
 	"sync"
 	"testing"
 	"time"
@@ -113,3 +117,714 @@ func TestTickDoesNotRestartCompletedOneTimeJob(t *testing.T) {
 
 	assert.True(t, runner.jobs[0].GetPhase().Is(JobPhaseReasonCompleted), "completed one-time job must stay completed")
 }
+
+func TestTickRespectsRestartPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		phase  JobPhaseReason
+	}{
+		{config.RestartPolicyNever, JobPhaseReasonFailed},
+		{config.RestartPolicyNever, JobPhaseReasonCompleted},
+		{config.RestartPolicyOnFailure, JobPhaseReasonCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy+"/"+string(tt.phase), func(t *testing.T) {
+			ignitionConfig := &config.Ignition{
+				Jobs: []config.JobConfig{
+					{
+						BaseJobConfig: config.BaseJobConfig{Name: "test-job", Command: "true"},
+						Restart:       &config.Restart{Policy: tt.policy},
+					},
+				},
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			runner := NewRunner(ctx, nil, true, ignitionConfig)
+			require.NoError(t, runner.Init())
+
+			runner.errChan = make(chan error, 16)
+			runner.waitGroup = &sync.WaitGroup{}
+
+			runner.jobs[0].GetPhase().Set(tt.phase)
+
+			runner.tick()
+
+			assert.True(t, runner.jobs[0].GetPhase().Is(tt.phase), "job must not be restarted")
+		})
+	}
+}
+
+func TestInitRejectsDependencyCycles(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{BaseJobConfig: config.BaseJobConfig{Name: "a", Command: "true"}, DependsOn: []string{"b"}},
+			{BaseJobConfig: config.BaseJobConfig{Name: "b", Command: "true"}, DependsOn: []string{"c"}},
+			{BaseJobConfig: config.BaseJobConfig{Name: "c", Command: "true"}, DependsOn: []string{"a"}},
+		},
+	}
+
+	runner := NewRunner(context.Background(), nil, false, ignitionConfig)
+	err := runner.Init()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a -> b -> c -> a")
+}
+
+func TestInitRejectsUnknownDependencies(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{BaseJobConfig: config.BaseJobConfig{Name: "nginx", Command: "true"}, DependsOn: []string{"php-fpm"}},
+		},
+	}
+
+	runner := NewRunner(context.Background(), nil, false, ignitionConfig)
+	err := runner.Init()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `depends on unknown job "php-fpm"`)
+}
+
+func TestInitSortsJobsByDependencies(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{BaseJobConfig: config.BaseJobConfig{Name: "nginx", Command: "true"}, DependsOn: []string{"php-fpm"}},
+			{BaseJobConfig: config.BaseJobConfig{Name: "php-fpm", Command: "true"}, DependsOn: []string{"db-migrate"}},
+			{BaseJobConfig: config.BaseJobConfig{Name: "db-migrate", Command: "true"}, OneTime: true},
+		},
+	}
+
+	runner := NewRunner(context.Background(), nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	var names []string
+	for _, job := range runner.jobs {
+		names = append(names, job.GetName())
+	}
+
+	assert.Equal(t, []string{"db-migrate", "php-fpm", "nginx"}, names)
+}
+
+func TestDependentJobWaitsForDependency(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{BaseJobConfig: config.BaseJobConfig{Name: "nginx", Command: "sleep", Args: []string{"10"}}, DependsOn: []string{"setup"}},
+			{BaseJobConfig: config.BaseJobConfig{Name: "setup", Command: "sleep", Args: []string{"0.5"}}, OneTime: true},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	nginx := runner.findJobByName("nginx")
+	setup := runner.findJobByName("setup")
+
+	require.Eventually(t, func() bool {
+		return setup.GetPhase().Is(JobPhaseReasonStarted)
+	}, 5*time.Second, 10*time.Millisecond, "setup job should be started")
+	assert.True(t, nginx.GetPhase().Is(JobPhaseReasonAwaitingDependencies), "nginx must wait for setup to complete")
+
+	require.Eventually(t, func() bool {
+		return nginx.GetPhase().Is(JobPhaseReasonStarted)
+	}, 5*time.Second, 50*time.Millisecond, "nginx should be started after setup completed")
+	assert.True(t, setup.GetPhase().Is(JobPhaseReasonCompleted))
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestDependentJobFailsWhenDependencyFails(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{BaseJobConfig: config.BaseJobConfig{Name: "nginx", Command: "sleep", Args: []string{"10"}}, DependsOn: []string{"setup"}},
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "setup", Command: "false", CanFail: true},
+				OneTime:       true,
+				Restart:       &config.Restart{Policy: config.RestartPolicyNever},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	nginx := runner.findCommonJobByName("nginx")
+	require.Eventually(t, func() bool {
+		return nginx.GetPhase().Is(JobPhaseReasonFailed)
+	}, 5*time.Second, 10*time.Millisecond, "nginx should fail when setup failed")
+
+	cmd, _ := nginx.command()
+	assert.Nil(t, cmd, "nginx must not be started")
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestFatalJobErrorStopsOtherJobs(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{BaseJobConfig: config.BaseJobConfig{Name: "sleeper", Command: "sleep", Args: []string{"30"}}},
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "failing", Command: "sh", Args: []string{"-c", "sleep 0.5; exit 1"}},
+				Restart:       &config.Restart{Policy: config.RestartPolicyNever},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- runner.Run()
+	}()
+
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "job failing failed")
+	case <-time.After(10 * time.Second):
+		t.Fatal("runner should return when a job fails")
+	}
+
+	sleeper := runner.findCommonJobByName("sleeper")
+	assert.True(t, sleeper.GetPhase().Is(JobPhaseReasonStopped), "sleeper should be stopped")
+	assert.False(t, sleeper.Status().Running, "sleeper process should be terminated")
+}
+
+func TestJobStartedViaAPIWaitsForDependencies(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{BaseJobConfig: config.BaseJobConfig{Name: "app", Command: "sleep", Args: []string{"10"}, Controllable: true}, DependsOn: []string{"db"}},
+			{BaseJobConfig: config.BaseJobConfig{Name: "db", Command: "sleep", Args: []string{"10"}, Controllable: true}},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, NewApi(""), false, ignitionConfig)
+	require.NoError(t, runner.Init())
+	runner.registerAPIV1Handlers()
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+
+	start := func(job string) {
+		rec := httptest.NewRecorder()
+		runner.api.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/job/"+job+"/start", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	app := runner.findCommonJobByName("app")
+	start("app")
+	assert.True(t, app.GetPhase().Is(JobPhaseReasonAwaitingDependencies), "app must wait for db")
+	assert.False(t, app.IsRunning())
+
+	start("db")
+	require.Eventually(t, app.IsRunning, 5*time.Second, 50*time.Millisecond, "app should be started after db")
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestDependentJobWaitsForReadiness(t *testing.T) {
+	readyDir := path.Join(t.TempDir(), "ready")
+
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "php-fpm", Command: "sleep", Args: []string{"10"}},
+				Readiness: &config.Readiness{
+					ProbeBackends: config.ProbeBackends{Filesystem: readyDir},
+					Interval:      "50ms",
+				},
+			},
+			{BaseJobConfig: config.BaseJobConfig{Name: "nginx", Command: "sleep", Args: []string{"10"}}, DependsOn: []string{"php-fpm"}},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	phpFpm := runner.findJobByName("php-fpm")
+	nginx := runner.findJobByName("nginx")
+
+	require.Eventually(t, func() bool {
+		return phpFpm.GetPhase().Is(JobPhaseReasonStarted)
+	}, 5*time.Second, 10*time.Millisecond, "php-fpm should be started")
+
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, nginx.GetPhase().Is(JobPhaseReasonAwaitingDependencies), "nginx must wait for php-fpm to become ready")
+	assert.False(t, runner.JobReadiness()["php-fpm"].OK)
+
+	require.NoError(t, os.Mkdir(readyDir, 0o755))
+
+	require.Eventually(t, func() bool {
+		return nginx.GetPhase().Is(JobPhaseReasonStarted)
+	}, 5*time.Second, 50*time.Millisecond, "nginx should be started after php-fpm became ready")
+	assert.True(t, phpFpm.GetPhase().Is(JobPhaseReasonReady))
+	assert.True(t, runner.JobReadiness()["php-fpm"].OK)
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestFailingLivenessCheckRestartsJob(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "hung-worker", Command: "sleep", Args: []string{"10"}},
+				Liveness: &config.Liveness{
+					ProbeBackends:    config.ProbeBackends{Filesystem: path.Join(t.TempDir(), "does-not-exist")},
+					Interval:         "50ms",
+					FailureThreshold: 2,
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	job := runner.findCommonJobByName("hung-worker")
+
+	var firstPid int
+	require.Eventually(t, func() bool {
+		firstPid = job.Status().Pid
+		return firstPid != 0
+	}, 5*time.Second, 10*time.Millisecond, "job should be started")
+
+	require.Eventually(t, func() bool {
+		status := job.Status()
+		return status.Pid != 0 && status.Pid != firstPid
+	}, 5*time.Second, 50*time.Millisecond, "job should be restarted")
+	assert.Contains(t, job.Status().LastRestartReason, "liveness check failed 2 times in a row")
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestApplyConfigOnlyRestartsChangedJobs(t *testing.T) {
+	sleepJob := func(name string, args ...string) config.JobConfig {
+		return config.JobConfig{BaseJobConfig: config.BaseJobConfig{Name: name, Command: "sleep", Args: args}}
+	}
+
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			sleepJob("unchanged", "10"),
+			sleepJob("changed", "10"),
+			sleepJob("removed", "10"),
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	pidOf := func(name string) int {
+		job := runner.findCommonJobByName(name)
+		if job == nil {
+			return 0
+		}
+		return job.Status().Pid
+	}
+
+	require.Eventually(t, func() bool {
+		return pidOf("unchanged") != 0 && pidOf("changed") != 0 && pidOf("removed") != 0
+	}, 5*time.Second, 10*time.Millisecond, "all jobs should be started")
+
+	unchangedPid := pidOf("unchanged")
+	changedPid := pidOf("changed")
+	removedJob := runner.findCommonJobByName("removed")
+
+	require.NoError(t, runner.ApplyConfig(&config.Ignition{
+		Jobs: []config.JobConfig{
+			sleepJob("unchanged", "10"),
+			sleepJob("changed", "20"),
+			sleepJob("added", "10"),
+		},
+	}))
+
+	require.Eventually(t, func() bool {
+		return pidOf("changed") != 0 && pidOf("added") != 0
+	}, 5*time.Second, 10*time.Millisecond, "changed and added jobs should be started")
+
+	assert.Equal(t, unchangedPid, pidOf("unchanged"), "unchanged job must keep running")
+	assert.NotEqual(t, changedPid, pidOf("changed"), "changed job must be restarted")
+	assert.Nil(t, runner.findJobByName("removed"), "removed job must not be managed anymore")
+	assert.False(t, removedJob.IsRunning(), "removed job must be stopped")
+
+	require.Error(t, runner.ApplyConfig(&config.Ignition{
+		Jobs: []config.JobConfig{
+			{BaseJobConfig: config.BaseJobConfig{Name: "broken", Command: "true"}, DependsOn: []string{"missing"}},
+		},
+	}))
+	assert.NotNil(t, runner.findJobByName("unchanged"), "invalid configuration must not be applied")
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestAddedJobsAreKeptOnReload(t *testing.T) {
+	sleepJob := func(name string) config.JobConfig {
+		return config.JobConfig{BaseJobConfig: config.BaseJobConfig{Name: name, Command: "sleep", Args: []string{"10"}}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, &config.Ignition{Jobs: []config.JobConfig{sleepJob("static")}})
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	invalid := sleepJob("invalid")
+	invalid.Restart = &config.Restart{Policy: "sometimes"}
+	assert.ErrorContains(t, runner.AddJob(&invalid), `unknown restart policy "sometimes"`)
+
+	added := sleepJob("added")
+	require.NoError(t, runner.AddJob(&added))
+	assert.ErrorIs(t, runner.AddJob(&added), ErrJobExists)
+
+	dependent := sleepJob("dependent")
+	dependent.DependsOn = []string{"added"}
+	require.NoError(t, runner.AddJob(&dependent))
+
+	require.Eventually(t, func() bool {
+		job := runner.findCommonJobByName("added")
+		return job != nil && job.IsRunning()
+	}, 5*time.Second, 10*time.Millisecond, "added job should be started")
+
+	addedJob := runner.findCommonJobByName("added")
+	pid := addedJob.Status().Pid
+
+	require.NoError(t, runner.ApplyConfig(&config.Ignition{Jobs: []config.JobConfig{sleepJob("static")}}))
+	assert.Same(t, addedJob, runner.findCommonJobByName("added"), "added job must be kept on reload")
+	assert.Equal(t, pid, addedJob.Status().Pid, "added job must not be restarted on reload")
+
+	assert.ErrorIs(t, runner.DeleteJob("added"), ErrJobIsRequired)
+	require.NoError(t, runner.DeleteJob("dependent"))
+	require.NoError(t, runner.DeleteJob("added"))
+	assert.Nil(t, runner.findJobByName("added"))
+	assert.False(t, addedJob.IsRunning(), "deleted job must be stopped")
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestScheduledJobRecordsLastRun(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "cleanup", Command: "false"},
+				Schedule:      &config.Schedule{Cron: "@every 1s"},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	job := runner.findCommonJobByName("cleanup")
+
+	require.Eventually(t, func() bool {
+		status := job.Status()
+		return status.Phase.Is(JobPhaseReasonScheduled) && status.Schedule.LastRun != nil
+	}, 5*time.Second, 50*time.Millisecond, "job should have run once")
+
+	lastRun := job.Status().Schedule.LastRun
+	assert.False(t, lastRun.Success)
+	assert.Equal(t, 1, lastRun.ExitCode)
+	assert.True(t, job.Status().Schedule.NextRun.After(lastRun.StartedAt))
+
+	cancel()
+	runner.shutdown()
+}
+
+// burstSchedule is due a few times in quick succession, and then not anymore.
+type burstSchedule struct {
+	lock      sync.Mutex
+	remaining int
+}
+
+func (s *burstSchedule) Next(t time.Time) time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.remaining > 0 {
+		s.remaining--
+		return t.Add(10 * time.Millisecond)
+	}
+	return t.Add(time.Hour)
+}
+
+func TestQueueOverlapPolicyQueuesAtMostOneRun(t *testing.T) {
+	runs := path.Join(t.TempDir(), "runs")
+
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "slow", Command: "sh", Args: []string{"-c", "echo run >> " + runs + "; sleep 0.3"}},
+				Schedule:      &config.Schedule{Cron: "@hourly", OverlapPolicy: config.OverlapPolicyQueue},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	job := runner.findCommonJobByName("slow")
+	job.schedule = &burstSchedule{remaining: 5}
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	// the first run is started, the next one is queued and the others are
+	// skipped, as they are all due while the first run is active
+	time.Sleep(1500 * time.Millisecond)
+
+	output, err := os.ReadFile(runs)
+	require.NoError(t, err)
+	assert.Equal(t, "run\nrun\n", string(output))
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestOnFailureRestartPolicyCompletesSuccessfulJob(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "succeeding", Command: "true"},
+				Restart:       &config.Restart{Policy: config.RestartPolicyOnFailure},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	job := runner.findCommonJobByName("succeeding")
+	require.Eventually(t, func() bool {
+		return job.GetPhase().Is(JobPhaseReasonCompleted)
+	}, 5*time.Second, 10*time.Millisecond, "job should be completed")
+	assert.Empty(t, runner.errChan)
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestStopKillsJobAfterStopTimeout(t *testing.T) {
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{
+				BaseJobConfig: config.BaseJobConfig{
+					Name:        "stubborn",
+					Command:     "sh",
+					Args:        []string{"-c", "trap '' TERM; sleep 30"},
+					StopTimeout: "200ms",
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	job := runner.findCommonJobByName("stubborn")
+	require.Eventually(t, func() bool {
+		return job.IsRunning()
+	}, 5*time.Second, 10*time.Millisecond, "job should be started")
+
+	job.Stop()
+
+	require.Eventually(t, func() bool {
+		return job.GetPhase().Is(JobPhaseReasonStopped)
+	}, 2*time.Second, 10*time.Millisecond, "job should be killed after its stop timeout")
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestLifecycleHooksAreExecuted(t *testing.T) {
+	output := path.Join(t.TempDir(), "output")
+	maxAttempts := 1
+	hook := func(name string) *config.HookCommand {
+		return &config.HookCommand{
+			WatchCommand: config.WatchCommand{Command: "sh", Args: []string{"-c", "echo " + name + " >> " + output}},
+		}
+	}
+
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "hooked", Command: "true"},
+				OneTime:       true,
+				PreStart:      hook("preStart"),
+				PostStart:     hook("postStart"),
+				PostStop:      hook("postStop"),
+			},
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "failing-hook", Command: "true", CanFail: true},
+				OneTime:       true,
+				MaxAttempts:   &maxAttempts,
+				PreStart: &config.HookCommand{
+					WatchCommand: config.WatchCommand{Command: "false"},
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	hooked := runner.findCommonJobByName("hooked")
+	require.Eventually(t, func() bool {
+		return hooked.GetPhase().Is(JobPhaseReasonCompleted)
+	}, 5*time.Second, 10*time.Millisecond, "job should be completed")
+
+	contents, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "preStart\npostStart\npostStop\n", string(contents))
+
+	failing := runner.findCommonJobByName("failing-hook")
+	require.Eventually(t, func() bool {
+		return failing.GetPhase().Is(JobPhaseReasonFailed)
+	}, 5*time.Second, 10*time.Millisecond, "job with failing preStart hook should fail")
+	cmd, _ := failing.command()
+	assert.Nil(t, cmd, "process should never have been started")
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestStopDoesNotWaitForPreStopHook(t *testing.T) {
+	output := path.Join(t.TempDir(), "output")
+	ignitionConfig := &config.Ignition{
+		Jobs: []config.JobConfig{
+			{
+				BaseJobConfig: config.BaseJobConfig{Name: "hooked", Command: "sleep", Args: []string{"30"}},
+				PreStop: &config.HookCommand{
+					WatchCommand: config.WatchCommand{Command: "sh", Args: []string{"-c", "sleep 0.5; echo preStop >> " + output}},
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := NewRunner(ctx, nil, false, ignitionConfig)
+	require.NoError(t, runner.Init())
+
+	runner.errChan = make(chan error, 16)
+	runner.waitGroup = &sync.WaitGroup{}
+	runner.exec()
+
+	job := runner.findCommonJobByName("hooked")
+	require.Eventually(t, func() bool {
+		return job.IsRunning()
+	}, 5*time.Second, 10*time.Millisecond, "job should be started")
+
+	stopCalled := time.Now()
+	job.Stop()
+	assert.Less(t, time.Since(stopCalled), 250*time.Millisecond, "stop should not wait for the preStop hook")
+
+	require.Eventually(t, func() bool {
+		return job.GetPhase().Is(JobPhaseReasonStopped)
+	}, 5*time.Second, 10*time.Millisecond, "job should be stopped after the preStop hook")
+
+	contents, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "preStop\n", string(contents))
+
+	cancel()
+	runner.shutdown()
+}
+
+func TestFingerprintCoversDeprecatedFields(t *testing.T) {
+	attempts := 5
+	jobConfig := config.JobConfig{BaseJobConfig: config.BaseJobConfig{Name: "web", Command: "/bin/web"}}
+
+	before, err := jobConfigFingerprint(&jobConfig)
+	require.NoError(t, err)
+
+	jobConfig.MaxAttempts_ = &attempts
+	after, err := jobConfigFingerprint(&jobConfig)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, before, after)
+}
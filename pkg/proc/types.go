@@ -2,59 +2,73 @@ package proc
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 
 	"github.com/mittwald/mittnite/internal/config"
+	"github.com/mittwald/mittnite/internal/helper"
+	"github.com/mittwald/mittnite/pkg/logrotate"
+	"github.com/mittwald/mittnite/pkg/probe"
+	"github.com/robfig/cron/v3"
 )
 
 const (
 	ShutdownWaitingTimeSeconds = 10
 )
 
-var TimeLayouts = map[string]string{
-	"RFC3339":     time.RFC3339,
-	"RFC3339Nano": time.RFC3339Nano,
-	"RFC1123":     time.RFC1123,
-	"RFC1123Z":    time.RFC1123Z,
-	"RFC822":      time.RFC822,
-	"RFC822Z":     time.RFC822Z,
-	"ANSIC":       time.ANSIC,
-	"UnixDate":    time.UnixDate,
-	"RubyDate":    time.RubyDate,
-	"Kitchen":     time.Kitchen,
-	"Stamp":       time.Stamp,
-	"StampMilli":  time.StampMilli,
-	"StampMicro":  time.StampMicro,
-	"StampNano":   time.StampNano,
-	"DateTime":    time.DateTime,
-	"DateOnly":    time.DateOnly,
-	"TimeOnly":    time.TimeOnly,
-}
+var TimeLayouts = config.TimeLayouts
 
 type Runner struct {
-	jobs        []Job
-	bootJobs    []*BootJob
-	api         *Api
-	waitGroup   *sync.WaitGroup
-	ctx         context.Context
-	errChan     chan error
-	keepRunning bool
+	jobs         []Job
+	jobsLock     sync.RWMutex // guards jobs and IgnitionConfig; writers also need to hold reloadLock
+	bootJobs     []*BootJob
+	api          *Api
+	waitGroup    *sync.WaitGroup
+	ctx          context.Context
+	errChan      chan error
+	shuttingDown chan struct{} // closed when all jobs are stopped because a job failed
+	keepRunning  bool
+
+	runningJobs     map[string]*runningJob
+	runningJobsLock sync.Mutex
+
+	configDir       string
+	jobFingerprints map[string]string
+	dynamicJobs     map[string]*config.JobConfig // jobs added via api
+	reloadLock      sync.Mutex
+
+	probes ProbeChecker
+	events *ringBuffer[Event] // recent state changes of jobs and probes
 
 	IgnitionConfig *config.Ignition
 }
 
+// runningJob keeps track of a job goroutine started by the runner, so that
+// jobs can be stopped one by one during shutdown.
+type runningJob struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
 type Api struct {
 	listenAddr string
 	srv        *http.Server
 	router     *mux.Router
 	upgrader   websocket.Upgrader
+	tlsConfig  *tls.Config
+
+	jobCreation bool // whether jobs can be created via api
 }
 
 func NewApi(listenAddress string) *Api {
@@ -73,16 +87,29 @@ type baseJob struct {
 
 	ctx       context.Context
 	interrupt context.CancelFunc
-	stdErrWg  sync.WaitGroup
-	stdOutWg  sync.WaitGroup
+	runLock   sync.Mutex // guards ctx, interrupt, cmd, exited, startedAt and lastExitCode
+	stdErrWg  *sync.WaitGroup
+	stdOutWg  *sync.WaitGroup
 
 	cmd       *exec.Cmd
-	restart   bool
-	stop      bool
-	stdout    *os.File
-	stderr    *os.File
+	exited    chan struct{} // closed when cmd has exited
+	restart   atomic.Bool
+	stop      atomic.Bool
+	stdout    io.Writer
+	stderr    io.Writer
+	logBuffer *logBuffer // output of streams without output file
 	lastError error
 	phase     JobPhase
+	events    *ringBuffer[Event] // event history of the runner; nil if the job is not managed by one
+
+	stopSignal  syscall.Signal
+	stopTimeout time.Duration
+	beforeStop  func() // called before the process is signalled to stop
+
+	// process statistics, exposed as metrics
+	startedAt    time.Time
+	lastExitCode *int
+	restarts     uint64
 }
 
 type BootJob struct {
@@ -97,13 +124,45 @@ type CommonJob struct {
 	Config *config.JobConfig
 
 	watchingFiles map[string]time.Time
+	restartPolicy *restartPolicy
+
+	readinessProbe     probe.Probe
+	readinessInterval  time.Duration
+	lastReadinessError error
+
+	livenessProbe            probe.Probe
+	livenessInterval         time.Duration
+	livenessFailureThreshold int
+	lastRestartReason        string
+
+	schedule        cron.Schedule
+	scheduleTimeout time.Duration
+	nextRun         time.Time
+	lastRun         *ScheduledRunResult
+
+	statusLock sync.Mutex // guards the fields reported by Status()
 }
 
 type CommonJobStatus struct {
-	Pid     int               `json:"pid,omitempty"`
-	Running bool              `json:"running"`
-	Phase   JobPhase          `json:"phase"`
-	Config  *config.JobConfig `json:"config"`
+	Pid               int               `json:"pid,omitempty"`
+	Running           bool              `json:"running"`
+	Phase             JobPhase          `json:"phase"`
+	LastRestartReason string            `json:"lastRestartReason,omitempty"`
+	Schedule          *ScheduleStatus   `json:"schedule,omitempty"`
+	Config            *config.JobConfig `json:"config"`
+}
+
+type ScheduleStatus struct {
+	NextRun time.Time           `json:"nextRun"`
+	LastRun *ScheduledRunResult `json:"lastRun,omitempty"`
+}
+
+type ScheduledRunResult struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Success    bool      `json:"success"`
+	ExitCode   int       `json:"exitCode"`
+	Error      string    `json:"error,omitempty"`
 }
 
 type LazyJob struct {
@@ -127,28 +186,79 @@ type Job interface {
 
 	GetPhase() *JobPhase
 	GetName() string
+	GetDependencies() []string
 }
 
 func newBaseJob(jobConfig *config.BaseJobConfig) (*baseJob, error) {
-	job := &baseJob{
-		Config:  jobConfig,
-		cmd:     nil,
-		restart: false,
-		stop:    false,
-		stdout:  os.Stdout,
-		stderr:  os.Stderr,
+	job := &baseJob{}
+	if err := job.init(jobConfig); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// init initialises the job in place; jobs must not be copied afterwards, as
+// callbacks like beforeStop are bound to the job's address.
+func (job *baseJob) init(jobConfig *config.BaseJobConfig) error {
+	if !config.ValidLogFormat(jobConfig.LogFormat) {
+		return fmt.Errorf("invalid logFormat %q", jobConfig.LogFormat)
 	}
+
+	job.Config = jobConfig
+	job.stdErrWg = &sync.WaitGroup{}
+	job.stdOutWg = &sync.WaitGroup{}
+	job.stdout = os.Stdout
+	job.stderr = os.Stderr
+	job.phase.job = jobConfig.Name
 	job.phase.Set(JobPhaseReasonAwaitingReadiness)
+
+	if jobConfig.Stdout == "" || jobConfig.Stderr == "" {
+		job.logBuffer = newLogBuffer(logBufferLines)
+	}
+
+	if err := job.initStopBehaviour(); err != nil {
+		return err
+	}
+
 	if len(jobConfig.Stdout) == 0 {
-		return job, nil
+		return nil
 	}
 
-	return job, job.CreateAndOpenStdFile(jobConfig)
+	// the output files are opened on every start of the process; they are
+	// only created here, so that invalid paths are reported early
+	if err := job.CreateAndOpenStdFile(jobConfig); err != nil {
+		return err
+	}
+	job.closeStdFiles()
+
+	return nil
+}
+
+func (job *baseJob) initStopBehaviour() error {
+	job.stopSignal = syscall.SIGTERM
+	if job.Config.StopSignal != "" {
+		sig, err := helper.ParseSignal(job.Config.StopSignal)
+		if err != nil {
+			return fmt.Errorf("invalid stopSignal: %w", err)
+		}
+		job.stopSignal = sig
+	}
+
+	job.stopTimeout = ShutdownWaitingTimeSeconds * time.Second
+	if job.Config.StopTimeout != "" {
+		t, err := time.ParseDuration(job.Config.StopTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid stopTimeout: %w", err)
+		}
+		job.stopTimeout = t
+	}
+
+	return nil
 }
 
 func (job *baseJob) CreateAndOpenStdFile(jobConfig *config.BaseJobConfig) error {
 	if jobConfig.Stdout != "" {
-		stdout, err := prepareStdFile(jobConfig.Stdout)
+		stdout, err := job.openStdFile(jobConfig.Stdout)
 		if err != nil {
 			return err
 		}
@@ -161,7 +271,7 @@ func (job *baseJob) CreateAndOpenStdFile(jobConfig *config.BaseJobConfig) error
 			return nil
 		}
 
-		stderr, err := prepareStdFile(jobConfig.Stderr)
+		stderr, err := job.openStdFile(jobConfig.Stderr)
 		if err != nil {
 			return err
 		}
@@ -171,30 +281,141 @@ func (job *baseJob) CreateAndOpenStdFile(jobConfig *config.BaseJobConfig) error
 	return nil
 }
 
-func NewCommonJob(c *config.JobConfig) (*CommonJob, error) {
-	job, err := newBaseJob(&c.BaseJobConfig)
+func (job *baseJob) openStdFile(filePath string) (io.Writer, error) {
+	if job.Config.LogRotation == nil {
+		return prepareStdFile(filePath)
+	}
+
+	options, err := logRotationOptions(job.Config.LogRotation)
 	if err != nil {
 		return nil, err
 	}
+	return logrotate.New(filePath, options)
+}
+
+func logRotationOptions(c *config.LogRotation) (logrotate.Options, error) {
+	options := logrotate.Options{
+		MaxFiles: c.MaxFiles,
+		Compress: c.Compress,
+	}
+
+	if c.MaxSize != "" {
+		size, err := helper.ParseByteSize(c.MaxSize)
+		if err != nil {
+			return options, fmt.Errorf("invalid logRotation maxSize: %w", err)
+		}
+		options.MaxSize = size
+	}
 
-	j := CommonJob{
-		baseJob: *job,
-		Config:  c,
+	if c.MaxAge != "" {
+		age, err := helper.ParseDuration(c.MaxAge)
+		if err != nil {
+			return options, fmt.Errorf("invalid logRotation maxAge: %w", err)
+		}
+		options.MaxAge = age
 	}
 
-	return &j, nil
+	return options, nil
 }
 
-func NewLazyJob(c *config.JobConfig) (*LazyJob, error) {
-	commonJob, err := NewCommonJob(c)
-	if err != nil {
+func NewCommonJob(c *config.JobConfig) (*CommonJob, error) {
+	j := &CommonJob{}
+	if err := j.init(c); err != nil {
 		return nil, err
 	}
+	return j, nil
+}
+
+func (j *CommonJob) init(c *config.JobConfig) error {
+	if err := j.baseJob.init(&c.BaseJobConfig); err != nil {
+		return err
+	}
+	j.Config = c
+
+	var err error
+	if c.Readiness != nil {
+		j.readinessProbe, err = probe.NewProbe(&c.Readiness.ProbeBackends)
+		if err != nil {
+			return fmt.Errorf("invalid readiness check: %w", err)
+		}
+		j.readinessProbe = probe.Instrument(j.readinessProbe, c.Name, probe.KindReadiness)
+
+		j.readinessInterval = 1 * time.Second
+		if c.Readiness.Interval != "" {
+			j.readinessInterval, err = time.ParseDuration(c.Readiness.Interval)
+			if err != nil {
+				return fmt.Errorf("invalid readiness interval: %w", err)
+			}
+		}
+	}
+
+	if c.Liveness != nil {
+		j.livenessProbe, err = probe.NewProbe(&c.Liveness.ProbeBackends)
+		if err != nil {
+			return fmt.Errorf("invalid liveness check: %w", err)
+		}
+		j.livenessProbe = probe.Instrument(j.livenessProbe, c.Name, probe.KindLiveness)
+
+		j.livenessInterval = 10 * time.Second
+		if c.Liveness.Interval != "" {
+			j.livenessInterval, err = time.ParseDuration(c.Liveness.Interval)
+			if err != nil {
+				return fmt.Errorf("invalid liveness interval: %w", err)
+			}
+		}
+
+		j.livenessFailureThreshold = 3
+		if c.Liveness.FailureThreshold > 0 {
+			j.livenessFailureThreshold = c.Liveness.FailureThreshold
+		}
+	}
 
-	commonJob.phase.Set(JobPhaseReasonAwaitingReadiness)
+	hooks := map[string]*config.HookCommand{"preStart": c.PreStart, "postStart": c.PostStart, "preStop": c.PreStop, "postStop": c.PostStop}
+	for name, hook := range hooks {
+		if hook == nil || hook.Timeout == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(hook.Timeout); err != nil {
+			return fmt.Errorf("invalid %s hook timeout: %w", name, err)
+		}
+	}
 
-	j := LazyJob{
-		CommonJob: *commonJob,
+	if c.PreStop != nil {
+		j.beforeStop = j.runPreStopHook
+	}
+
+	j.restartPolicy, err = newRestartPolicy(c)
+	if err != nil {
+		return fmt.Errorf("invalid restart configuration: %w", err)
+	}
+
+	if c.Schedule != nil {
+		j.schedule, err = cron.ParseStandard(c.Schedule.Cron)
+		if err != nil {
+			return fmt.Errorf("invalid cron expression %q: %w", c.Schedule.Cron, err)
+		}
+
+		switch c.Schedule.OverlapPolicy {
+		case "", config.OverlapPolicySkip, config.OverlapPolicyQueue, config.OverlapPolicyReplace:
+		default:
+			return fmt.Errorf("invalid overlap policy %q", c.Schedule.OverlapPolicy)
+		}
+
+		if c.Schedule.Timeout != "" {
+			j.scheduleTimeout, err = time.ParseDuration(c.Schedule.Timeout)
+			if err != nil {
+				return fmt.Errorf("invalid schedule timeout: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func NewLazyJob(c *config.JobConfig) (*LazyJob, error) {
+	j := &LazyJob{}
+	if err := j.CommonJob.init(c); err != nil {
+		return nil, err
 	}
 
 	if c.Laziness.SpinUpTimeout != "" {
@@ -219,16 +440,27 @@ func NewLazyJob(c *config.JobConfig) (*LazyJob, error) {
 		j.coolDownTimeout = 15 * time.Minute
 	}
 
-	return &j, nil
+	return j, nil
 }
 
 func NewBootJob(c *config.BootJobConfig) (*BootJob, error) {
 	bj := BootJob{
 		baseJob: baseJob{
 			Config: &c.BaseJobConfig,
+			stdout: os.Stdout,
+			stderr: os.Stderr,
 		},
 		Config: c,
 	}
+	bj.phase.job = c.Name
+
+	if !config.ValidLogFormat(c.LogFormat) {
+		return nil, fmt.Errorf("invalid logFormat %q", c.LogFormat)
+	}
+
+	if err := bj.initStopBehaviour(); err != nil {
+		return nil, err
+	}
 
 	if ts := c.Timeout; ts != "" {
 		t, err := time.ParseDuration(ts)
@@ -1,40 +1,106 @@
 package proc
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 type JobPhaseReason string
 
 const (
-	JobPhaseReasonUnknown            JobPhaseReason = "unknown"
-	JobPhaseReasonAwaitingReadiness  JobPhaseReason = "awaitingReadiness"
-	JobPhaseReasonAwaitingConnection JobPhaseReason = "awaitingConnection"
-	JobPhaseReasonStarted            JobPhaseReason = "started"
-	JobPhaseReasonStopped            JobPhaseReason = "stopped"
-	JobPhaseReasonCompleted          JobPhaseReason = "completed"
-	JobPhaseReasonFailed             JobPhaseReason = "failed"
-	JobPhaseReasonCrashLooping       JobPhaseReason = "crashLooping"
+	JobPhaseReasonUnknown              JobPhaseReason = "unknown"
+	JobPhaseReasonAwaitingReadiness    JobPhaseReason = "awaitingReadiness"
+	JobPhaseReasonAwaitingDependencies JobPhaseReason = "awaitingDependencies"
+	JobPhaseReasonAwaitingConnection   JobPhaseReason = "awaitingConnection"
+	JobPhaseReasonScheduled            JobPhaseReason = "scheduled"
+	JobPhaseReasonStarted              JobPhaseReason = "started"
+	JobPhaseReasonReady                JobPhaseReason = "ready"
+	JobPhaseReasonStopped              JobPhaseReason = "stopped"
+	JobPhaseReasonCompleted            JobPhaseReason = "completed"
+	JobPhaseReasonFailed               JobPhaseReason = "failed"
+	JobPhaseReasonCrashLooping         JobPhaseReason = "crashLooping"
 )
 
+var allJobPhaseReasons = []JobPhaseReason{
+	JobPhaseReasonUnknown,
+	JobPhaseReasonAwaitingReadiness,
+	JobPhaseReasonAwaitingDependencies,
+	JobPhaseReasonAwaitingConnection,
+	JobPhaseReasonScheduled,
+	JobPhaseReasonStarted,
+	JobPhaseReasonReady,
+	JobPhaseReasonStopped,
+	JobPhaseReasonCompleted,
+	JobPhaseReasonFailed,
+	JobPhaseReasonCrashLooping,
+}
+
 type JobPhase struct {
 	Reason     JobPhaseReason `json:"reason"`
 	LastChange time.Time      `json:"lastChange"`
+
+	job    string             // name of the job; used for events
+	events *ringBuffer[Event] // event history that phase changes are published to
+	lock   sync.Mutex         // guards Reason, LastChange and events
 }
 
 func (p *JobPhase) Set(reason JobPhaseReason) {
 	if p == nil {
 		p = &JobPhase{}
 	}
+
+	p.lock.Lock()
 	if p.Reason == reason {
+		p.lock.Unlock()
 		return
 	}
 
+	previous := p.Reason
 	p.LastChange = time.Now()
 	p.Reason = reason
+	events := p.events
+	p.lock.Unlock()
+
+	if p.job != "" {
+		publishEvent(events, Event{
+			Type:          EventTypeJobPhase,
+			Job:           p.job,
+			Phase:         reason,
+			PreviousPhase: previous,
+		})
+	}
 }
 
 func (p *JobPhase) Is(reason JobPhaseReason) bool {
 	if p == nil {
 		return false
 	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
 	return p.Reason == reason
 }
+
+// Get returns a copy of the phase, which can be used without locking.
+func (p *JobPhase) Get() JobPhase {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return JobPhase{Reason: p.Reason, LastChange: p.LastChange}
+}
+
+func (p *JobPhase) setEvents(events *ringBuffer[Event]) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.events = events
+}
+
+func (p *JobPhase) reset() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.Reason = ""
+	p.LastChange = time.Time{}
+}
@@ -0,0 +1,146 @@
+// Package reaper reaps zombie processes that are adopted by mittnite when it
+// runs as init process. Commands that are run via Run are excluded, so that
+// their exit status can be awaited reliably (e.g. by exec probes).
+package reaper
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// lock is held while starting processes via Run and while reaping, so
+	// that such a process is never mistaken for an orphan
+	lock    sync.Mutex
+	managed = make(map[int]struct{})
+)
+
+// start starts the given command; its process is not reaped by ReapChildren,
+// so wait needs to be called to release it.
+func start(cmd *exec.Cmd) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	managed[cmd.Process.Pid] = struct{}{}
+	return nil
+}
+
+// wait waits for a command started by start to exit.
+func wait(cmd *exec.Cmd) error {
+	err := cmd.Wait()
+
+	lock.Lock()
+	delete(managed, cmd.Process.Pid)
+	lock.Unlock()
+
+	return err
+}
+
+// Run starts the given command and waits for it to exit; its process is not
+// reaped by ReapChildren.
+func Run(cmd *exec.Cmd) error {
+	if err := start(cmd); err != nil {
+		return err
+	}
+	return wait(cmd)
+}
+
+// CombinedOutput runs the given command and returns its combined stdout and
+// stderr.
+func CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := Run(cmd)
+	return out.Bytes(), err
+}
+
+// ReapChildren reaps all terminated child processes, except for those of
+// commands that are run via Run.
+func ReapChildren() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGCHLD)
+
+	for sig := range signals {
+		log.WithField("signal", sig).Debug("handling signal")
+		reapZombies()
+	}
+}
+
+func reapZombies() {
+	lock.Lock()
+	defer lock.Unlock()
+
+	for _, pid := range zombieChildren() {
+		if _, ok := managed[pid]; ok {
+			continue
+		}
+
+		var s syscall.WaitStatus
+		_, err := syscall.Wait4(pid, &s, syscall.WNOHANG, nil)
+		for err == syscall.EINTR {
+			_, err = syscall.Wait4(pid, &s, syscall.WNOHANG, nil)
+		}
+
+		if err != nil {
+			log.WithField("pid", pid).WithError(err).Warn("failed to reap child")
+			continue
+		}
+
+		log.WithField("pid", pid).WithField("status", s).
+			Info("reaped child")
+	}
+}
+
+// zombieChildren returns the process ids of all terminated child processes
+// that have not been reaped yet.
+func zombieChildren() []int {
+	entries, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return nil
+	}
+
+	self := os.Getpid()
+
+	var pids []int
+	for _, entry := range entries {
+		stat, err := os.ReadFile(entry)
+		if err != nil {
+			continue
+		}
+
+		// the process name is enclosed in parentheses and may contain spaces;
+		// it is followed by the state and the parent's process id
+		i := bytes.LastIndexByte(stat, ')')
+		if i < 0 {
+			continue
+		}
+		fields := bytes.Fields(stat[i+1:])
+		if len(fields) < 2 || string(fields[0]) != "Z" {
+			continue
+		}
+
+		if ppid, _ := strconv.Atoi(string(fields[1])); ppid != self {
+			continue
+		}
+
+		if pid, err := strconv.Atoi(filepath.Base(filepath.Dir(entry))); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+
+	return pids
+}
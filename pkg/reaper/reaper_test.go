@@ -0,0 +1,54 @@
+package reaper
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var startReaper = sync.OnceFunc(func() {
+	go ReapChildren()
+})
+
+func TestManagedProcessesAreNotReaped(t *testing.T) {
+	startReaper()
+
+	// keep a child running, so that there is always something to wait for
+	sleeper := exec.Command("sleep", "10")
+	require.NoError(t, start(sleeper))
+	defer func() {
+		_ = sleeper.Process.Kill()
+		_ = wait(sleeper)
+	}()
+
+	for i := 0; i < 20; i++ {
+		err := Run(exec.Command("sh", "-c", "exit 3"))
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+	}
+
+	out, err := CombinedOutput(exec.Command("sh", "-c", "echo out; echo err >&2"))
+	require.NoError(t, err)
+	assert.Equal(t, "out\nerr\n", string(out))
+}
+
+func TestUnmanagedProcessesAreReaped(t *testing.T) {
+	startReaper()
+
+	pid, err := syscall.ForkExec("/bin/true", []string{"true"}, &syscall.ProcAttr{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat("/proc/" + strconv.Itoa(pid))
+		return os.IsNotExist(err)
+	}, 5*time.Second, 10*time.Millisecond)
+}